@@ -0,0 +1,406 @@
+package ipx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"sync"
+	"time"
+)
+
+// maxAddrAllocatorSize is the maximum number of addresses of AddrAllocator,
+// so the bitmaps take at most 2 MiB each.
+const maxAddrAllocatorSize = 1 << 24
+
+// addrAllocatorVersion is the current version of the binary state.
+const addrAllocatorVersion = 1
+
+// AddrAllocator hands out single addresses of a network or IP range
+// to clients as leases. Each client identified by a key gets at most
+// one address. An expired lease is kept for its client until another
+// client needs the address, so clients usually get the same address back.
+//
+// The state is kept as bitmaps, the number of addresses is limited to 2^24.
+// AddrAllocator is safe for concurrent use.
+type AddrAllocator struct {
+	// Now is the clock used for lease expiry.
+	// time.Now is used if nil. Should be set before use.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	first    Uint128
+	v6       bool
+	size     uint32
+	next     uint32 // index to start the search of free address from
+	reserved bitmap
+	leased   bitmap
+	leases   map[uint32]addrLease
+	keys     map[string]uint32
+}
+
+// addrLease is the lease of an address.
+type addrLease struct {
+	key     string
+	expires time.Time
+}
+
+// NewAddrAllocator returns an allocator of the network hosts,
+// the same addresses as Hosts() returns, so network and broadcast
+// addresses are never handed out.
+func NewAddrAllocator(network *net.IPNet) (*AddrAllocator, error) {
+	span, v6, err := netSpan(network)
+	if err != nil {
+		return nil, err
+	}
+	if span.last.Sub(span.first).Cmp64(2) < 0 {
+		return nil, fmt.Errorf("%w: %s has no hosts", ErrInvalidNetwork, network)
+	}
+
+	span.first = span.first.Add64(1)
+	span.last = span.last.Sub64(1)
+	return newAddrAllocator(span, v6)
+}
+
+// NewAddrAllocatorRange returns an allocator of all the range addresses.
+func NewAddrAllocatorRange(r Range) (*AddrAllocator, error) {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return nil, err
+	}
+	if span.empty() {
+		return nil, fmt.Errorf("%w: %s is empty", ErrInvalidRange, r)
+	}
+	return newAddrAllocator(span, v6)
+}
+
+// newAddrAllocator returns an allocator of the span.
+func newAddrAllocator(span ipSpan, v6 bool) (*AddrAllocator, error) {
+	if span.last.Sub(span.first).Cmp64(maxAddrAllocatorSize) >= 0 {
+		return nil, fmt.Errorf("%w: more than %d addresses", ErrOverflow, maxAddrAllocatorSize)
+	}
+
+	size := uint32(span.last.Sub(span.first).Lo) + 1
+	return &AddrAllocator{
+		first:    span.first,
+		v6:       v6,
+		size:     size,
+		reserved: newBitmap(size),
+		leased:   newBitmap(size),
+		leases:   make(map[uint32]addrLease),
+		keys:     make(map[string]uint32),
+	}, nil
+}
+
+// Reserve excludes the address from allocation.
+// ErrAllocated is returned if the address is leased.
+func (a *AddrAllocator) Reserve(addr net.IP) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i, err := a.index(addr)
+	if err != nil {
+		return err
+	}
+	if a.leased.get(i) {
+		if !a.expired(a.leases[i], a.now()) {
+			return fmt.Errorf("%w: %s", ErrAllocated, addr)
+		}
+		a.drop(i)
+	}
+
+	a.reserved.set(i)
+	return nil
+}
+
+// Lease returns the address leased to the client for the given duration.
+// If the client already has an address (even expired but not reused),
+// the lease is renewed and the same address is returned.
+// ErrExhausted is returned if there are no free addresses.
+func (a *AddrAllocator) Lease(key string, ttl time.Duration) (net.IP, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if i, ok := a.keys[key]; ok {
+		a.leases[i] = addrLease{key: key, expires: now.Add(ttl)}
+		return a.addr(i), nil
+	}
+
+	i, ok := a.free(now)
+	if !ok {
+		return nil, ErrExhausted
+	}
+
+	a.leased.set(i)
+	a.leases[i] = addrLease{key: key, expires: now.Add(ttl)}
+	a.keys[key] = i
+	a.next = (i + 1) % a.size
+	return a.addr(i), nil
+}
+
+// Renew extends the active lease of the client.
+// ErrNotAllocated is returned if the client has no active lease.
+func (a *AddrAllocator) Renew(key string, ttl time.Duration) (net.IP, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	i, ok := a.keys[key]
+	if !ok || a.expired(a.leases[i], now) {
+		return nil, fmt.Errorf("%w: %q", ErrNotAllocated, key)
+	}
+
+	a.leases[i] = addrLease{key: key, expires: now.Add(ttl)}
+	return a.addr(i), nil
+}
+
+// Release frees the address of the client.
+// ErrNotAllocated is returned if the client has no address.
+func (a *AddrAllocator) Release(key string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i, ok := a.keys[key]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotAllocated, key)
+	}
+
+	a.drop(i)
+	return nil
+}
+
+// Lookup returns the address of the client and its lease expiry time.
+// Returns false if the client has no address.
+func (a *AddrAllocator) Lookup(key string) (net.IP, time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i, ok := a.keys[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return a.addr(i), a.leases[i].expires, true
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (a *AddrAllocator) MarshalBinary() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	buf := make([]byte, 2+net.IPv6len+8, 2+net.IPv6len+8+8*len(a.reserved)+4)
+	buf[0] = addrAllocatorVersion
+	if a.v6 {
+		buf[1] = 1
+	}
+	store128(a.first, buf[2:2+net.IPv6len])
+	binary.BigEndian.PutUint32(buf[2+net.IPv6len:], a.size)
+	binary.BigEndian.PutUint32(buf[2+net.IPv6len+4:], a.next)
+	var tmp [binary.MaxVarintLen64]byte
+	for _, w := range a.reserved {
+		binary.BigEndian.PutUint64(tmp[:], w)
+		buf = append(buf, tmp[:8]...)
+	}
+
+	binary.BigEndian.PutUint32(tmp[:], uint32(len(a.leases)))
+	buf = append(buf, tmp[:4]...)
+	for i := a.leased.nextSet(0); i < a.size; i = a.leased.nextSet(i + 1) {
+		l := a.leases[i]
+		binary.BigEndian.PutUint32(tmp[:], i)
+		buf = append(buf, tmp[:4]...)
+		binary.BigEndian.PutUint64(tmp[:], uint64(l.expires.UnixNano()))
+		buf = append(buf, tmp[:8]...)
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(l.key)))]...)
+		buf = append(buf, l.key...)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The current state of the allocator is replaced, the clock is kept.
+func (a *AddrAllocator) UnmarshalBinary(data []byte) error {
+	const header = 2 + net.IPv6len + 8
+	if len(data) < header || data[0] != addrAllocatorVersion || data[1] > 1 {
+		return ErrInvalidAllocatorState
+	}
+
+	first := load128(data[2 : 2+net.IPv6len])
+	size := binary.BigEndian.Uint32(data[2+net.IPv6len:])
+	last := first.Add64(uint64(size) - 1)
+	if size == 0 || size > maxAddrAllocatorSize || last.Cmp(first) < 0 ||
+		(data[1] == 0 && last.Cmp64(maxUint32) > 0) {
+		return ErrInvalidAllocatorState
+	}
+
+	out, err := newAddrAllocator(ipSpan{first: first, last: last}, data[1] == 1)
+	if err != nil {
+		return ErrInvalidAllocatorState
+	}
+	out.next = binary.BigEndian.Uint32(data[2+net.IPv6len+4:]) % size
+
+	data = data[header:]
+	if len(data) < 8*len(out.reserved)+4 {
+		return ErrInvalidAllocatorState
+	}
+	for k := range out.reserved {
+		out.reserved[k] = binary.BigEndian.Uint64(data[8*k:])
+	}
+	data = data[8*len(out.reserved):]
+	if out.reserved.nextSet(size) < uint32(64*len(out.reserved)) {
+		return ErrInvalidAllocatorState // bits beyond the size
+	}
+
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	for ; n > 0; n-- {
+		if len(data) < 12 {
+			return ErrInvalidAllocatorState
+		}
+		i := binary.BigEndian.Uint32(data)
+		expires := time.Unix(0, int64(binary.BigEndian.Uint64(data[4:])))
+		klen, k := binary.Uvarint(data[12:])
+		if k <= 0 || uint64(len(data)-12-k) < klen {
+			return ErrInvalidAllocatorState
+		}
+		key := string(data[12+k : 12+k+int(klen)])
+		data = data[12+k+int(klen):]
+
+		if _, dup := out.keys[key]; dup || i >= size || out.leased.get(i) || out.reserved.get(i) {
+			return ErrInvalidAllocatorState
+		}
+		out.leased.set(i)
+		out.leases[i] = addrLease{key: key, expires: expires}
+		out.keys[key] = i
+	}
+	if len(data) != 0 {
+		return ErrInvalidAllocatorState
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.first, a.v6, a.size, a.next = out.first, out.v6, out.size, out.next
+	a.reserved, a.leased = out.reserved, out.leased
+	a.leases, a.keys = out.leases, out.keys
+	return nil
+}
+
+// free returns the index of a free address. Never leased addresses
+// are preferred, then the address with the oldest expired lease is reused.
+func (a *AddrAllocator) free(now time.Time) (uint32, bool) {
+	for _, from := range []uint32{a.next, 0} {
+		if i := a.leased.nextClear(from, a.reserved); i < a.size {
+			return i, true
+		}
+	}
+
+	var found uint32
+	var oldest *addrLease
+	for i, l := range a.leases {
+		l := l
+		if a.expired(l, now) && (oldest == nil || l.expires.Before(oldest.expires) ||
+			(l.expires.Equal(oldest.expires) && i < found)) {
+			found, oldest = i, &l
+		}
+	}
+	if oldest == nil {
+		return 0, false
+	}
+
+	a.drop(found)
+	return found, true
+}
+
+// drop removes the lease of the address.
+func (a *AddrAllocator) drop(i uint32) {
+	delete(a.keys, a.leases[i].key)
+	delete(a.leases, i)
+	a.leased.clear(i)
+}
+
+// index returns the index of the address.
+func (a *AddrAllocator) index(addr net.IP) (uint32, error) {
+	u, v6, err := loadIP(addr)
+	if err != nil {
+		return 0, err
+	}
+	if v6 != a.v6 {
+		return 0, ErrVersionMismatch
+	}
+
+	d := u.Sub(a.first)
+	if u.Cmp(a.first) < 0 || d.Cmp64(uint64(a.size)) >= 0 {
+		return 0, fmt.Errorf("%w: %s is out of pool", ErrInvalidIP, addr)
+	}
+	return uint32(d.Lo), nil
+}
+
+// addr returns the address of the index.
+func (a *AddrAllocator) addr(i uint32) net.IP {
+	return storeIP(a.first.Add64(uint64(i)), a.v6)
+}
+
+// expired returns true if the lease is over.
+func (a *AddrAllocator) expired(l addrLease, now time.Time) bool {
+	return !now.Before(l.expires)
+}
+
+// now returns the current time.
+func (a *AddrAllocator) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+// bitmap is a set of small integers.
+type bitmap []uint64
+
+// newBitmap returns a bitmap for integers less than size.
+func newBitmap(size uint32) bitmap {
+	return make(bitmap, (uint64(size)+63)/64)
+}
+
+func (b bitmap) get(i uint32) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b bitmap) set(i uint32) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitmap) clear(i uint32) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+// nextSet returns the first set integer starting from i
+// or the bitmap capacity if there is no such integer.
+func (b bitmap) nextSet(i uint32) uint32 {
+	for k := int(i / 64); k < len(b); k++ {
+		w := b[k]
+		if k == int(i/64) {
+			w &^= 1<<(i%64) - 1 // skip lower bits
+		}
+		if w != 0 {
+			return uint32(64*k + bits.TrailingZeros64(w))
+		}
+	}
+	return uint32(64 * len(b))
+}
+
+// nextClear returns the first integer starting from i which is set
+// neither in this nor in the other bitmap or the bitmap capacity
+// if there is no such integer.
+func (b bitmap) nextClear(i uint32, other bitmap) uint32 {
+	for k := int(i / 64); k < len(b); k++ {
+		w := ^(b[k] | other[k])
+		if k == int(i/64) {
+			w &^= 1<<(i%64) - 1 // skip lower bits
+		}
+		if w != 0 {
+			return uint32(64*k + bits.TrailingZeros64(w))
+		}
+	}
+	return uint32(64 * len(b))
+}
@@ -0,0 +1,195 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleAddrAllocator is an example of address leasing
+func ExampleAddrAllocator() {
+	a, _ := ipx.NewAddrAllocator(cidr("10.0.0.0/29"))
+	_ = a.Reserve(net.ParseIP("10.0.0.1")) // gateway
+	ip1, _ := a.Lease("host-1", time.Hour)
+	ip2, _ := a.Lease("host-2", time.Hour)
+	ip3, _ := a.Lease("host-1", time.Hour) // sticky
+	fmt.Println(ip1, ip2, ip3)
+	// Output:
+	// 10.0.0.2 10.0.0.3 10.0.0.2
+}
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// TestAddrAllocator unit tests for AddrAllocator
+func TestAddrAllocator(tt *testing.T) {
+	tt.Run("lease", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		a, err := ipx.NewAddrAllocator(cidr("10.0.0.0/30"))
+		require.NoError(t, err)
+		a.Now = clock.Now
+
+		ip, err := a.Lease("a", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1", ip.String())
+		ip, err = a.Lease("b", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.2", ip.String())
+		_, err = a.Lease("c", time.Minute)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+
+		// renew
+		clock.now = clock.now.Add(30 * time.Second)
+		ip, err = a.Renew("a", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1", ip.String())
+		_, expires, ok := a.Lookup("a")
+		require.True(t, ok)
+		assert.Equal(t, clock.now.Add(time.Minute), expires)
+
+		// expired lease of "b" is reused
+		clock.now = clock.now.Add(45 * time.Second)
+		_, err = a.Renew("b", time.Minute)
+		assert.ErrorIs(t, err, ipx.ErrNotAllocated)
+		ip, err = a.Lease("c", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.2", ip.String())
+		_, _, ok = a.Lookup("b")
+		assert.False(t, ok)
+
+		// release
+		require.NoError(t, a.Release("a"))
+		assert.ErrorIs(t, a.Release("a"), ipx.ErrNotAllocated)
+		ip, err = a.Lease("d", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1", ip.String())
+	})
+
+	tt.Run("sticky", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		a, err := ipx.NewAddrAllocatorRange(ipx.NewRange(net.ParseIP("2001:db8::10"), net.ParseIP("2001:db8::13")))
+		require.NoError(t, err)
+		a.Now = clock.Now
+
+		ip, err := a.Lease("a", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8::10", ip.String())
+
+		// expired but never leased addresses are preferred
+		clock.now = clock.now.Add(time.Hour)
+		ip, err = a.Lease("b", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8::11", ip.String())
+		ip, err = a.Lease("a", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8::10", ip.String())
+	})
+
+	tt.Run("reserve", func(t *testing.T) {
+		a, err := ipx.NewAddrAllocator(cidr("10.0.0.0/29"))
+		require.NoError(t, err)
+
+		require.NoError(t, a.Reserve(net.ParseIP("10.0.0.1")))
+		require.NoError(t, a.Reserve(net.ParseIP("10.0.0.3")))
+		var got []string
+		for i := 0; i < 4; i++ {
+			ip, err := a.Lease(fmt.Sprint(i), time.Hour)
+			require.NoError(t, err)
+			got = append(got, ip.String())
+		}
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.4", "10.0.0.5", "10.0.0.6"}, got)
+
+		assert.ErrorIs(t, a.Reserve(net.ParseIP("10.0.0.2")), ipx.ErrAllocated)
+		assert.ErrorIs(t, a.Reserve(net.ParseIP("10.0.0.7")), ipx.ErrInvalidIP)
+		assert.ErrorIs(t, a.Reserve(net.ParseIP("2001:db8::1")), ipx.ErrVersionMismatch)
+	})
+
+	tt.Run("bad", func(t *testing.T) {
+		_, err := ipx.NewAddrAllocator(cidr("10.0.0.0/31"))
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.NewAddrAllocator(cidr("2001:db8::/64"))
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.NewAddrAllocatorRange(ipx.NewRange(net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")))
+		assert.ErrorIs(t, err, ipx.ErrInvalidRange)
+	})
+
+	tt.Run("binary", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		a, err := ipx.NewAddrAllocator(cidr("10.0.0.0/24"))
+		require.NoError(t, err)
+		a.Now = clock.Now
+
+		require.NoError(t, a.Reserve(net.ParseIP("10.0.0.100")))
+		for i := 0; i < 3; i++ {
+			_, err = a.Lease(fmt.Sprint("host-", i), time.Minute)
+			require.NoError(t, err)
+		}
+		data, err := a.MarshalBinary()
+		require.NoError(t, err)
+
+		var b ipx.AddrAllocator
+		b.Now = clock.Now
+		require.NoError(t, b.UnmarshalBinary(data))
+		ip, expires, ok := b.Lookup("host-1")
+		require.True(t, ok)
+		assert.Equal(t, "10.0.0.2", ip.String())
+		assert.Equal(t, clock.now.Add(time.Minute).UnixNano(), expires.UnixNano())
+		ip, err = b.Lease("host-3", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.4", ip.String())
+		assert.ErrorIs(t, b.Reserve(net.ParseIP("10.0.0.3")), ipx.ErrAllocated)
+
+		data2, err := b.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, b.UnmarshalBinary(data2))
+
+		for _, bad := range [][]byte{
+			nil,
+			data[:10],
+			data[:len(data)-1],
+			append(append([]byte(nil), data...), 0),
+			append([]byte{2}, data[1:]...),
+		} {
+			assert.ErrorIs(t, b.UnmarshalBinary(bad), ipx.ErrInvalidAllocatorState)
+		}
+	})
+
+	tt.Run("concurrent", func(t *testing.T) {
+		a, err := ipx.NewAddrAllocator(cidr("10.0.0.0/24"))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		seen := make(map[string]string)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for k := 0; k < 32; k++ {
+					key := fmt.Sprint(i, "-", k)
+					ip, err := a.Lease(key, time.Hour)
+					if err != nil {
+						return
+					}
+					mu.Lock()
+					seen[ip.String()] = key
+					mu.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Len(t, seen, 254)
+	})
+}
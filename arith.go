@@ -0,0 +1,179 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
+)
+
+// AddIP returns the address offset positions after addr.
+// Unlike NextIP it never wraps around: ErrOverflow is returned instead.
+func AddIP(addr net.IP, offset Uint128) (net.IP, error) {
+	key, v6, err := ipKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	if key, err = key.add(offset); err != nil {
+		return nil, fmt.Errorf("%w: %s + %s", err, addr, offset)
+	}
+	return keyNet(key, v6).IP, nil
+}
+
+// SubIP returns the address offset positions before addr.
+// Unlike NextIP it never wraps around: ErrOverflow is returned instead.
+func SubIP(addr net.IP, offset Uint128) (net.IP, error) {
+	key, v6, err := ipKey(addr)
+	if err != nil {
+		return nil, err
+	}
+	if key, err = key.sub(offset); err != nil {
+		return nil, fmt.Errorf("%w: %s - %s", err, addr, offset)
+	}
+	return keyNet(key, v6).IP, nil
+}
+
+// StepIP is the same as NextIP but returns ErrOverflow
+// instead of wrapping around.
+func StepIP(addr net.IP, step int64) (net.IP, error) {
+	if step < 0 {
+		return SubIP(addr, u128.From64(uint64(-step)))
+	}
+	return AddIP(addr, u128.From64(uint64(step)))
+}
+
+// AddNetwork returns the network offset positions after the network
+// (of the same mask). ErrOverflow is returned instead of wrapping around.
+// Host bits of the network are ignored.
+func AddNetwork(network *net.IPNet, offset Uint128) (*net.IPNet, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return nil, err
+	}
+	if key, err = key.add(offset); err != nil {
+		return nil, fmt.Errorf("%w: %s + %s", err, network, offset)
+	}
+	return keyNet(key, v6), nil
+}
+
+// SubNetwork returns the network offset positions before the network
+// (of the same mask). ErrOverflow is returned instead of wrapping around.
+// Host bits of the network are ignored.
+func SubNetwork(network *net.IPNet, offset Uint128) (*net.IPNet, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return nil, err
+	}
+	if key, err = key.sub(offset); err != nil {
+		return nil, fmt.Errorf("%w: %s - %s", err, network, offset)
+	}
+	return keyNet(key, v6), nil
+}
+
+// StepNetwork is the same as NextNetwork but returns ErrOverflow
+// instead of wrapping around.
+func StepNetwork(network *net.IPNet, step int64) (*net.IPNet, error) {
+	if step < 0 {
+		return SubNetwork(network, u128.From64(uint64(-step)))
+	}
+	return AddNetwork(network, u128.From64(uint64(step)))
+}
+
+// Distance returns the number of positions between two addresses,
+// regardless of their order.
+func Distance(a, b net.IP) (Uint128, error) {
+	ka, v6, err := ipKey(a)
+	if err != nil {
+		return Uint128{}, err
+	}
+	kb, bv6, err := ipKey(b)
+	if err != nil {
+		return Uint128{}, err
+	}
+	if v6 != bv6 {
+		return Uint128{}, ErrVersionMismatch
+	}
+
+	if ka.addr.Cmp(kb.addr) > 0 {
+		ka, kb = kb, ka
+	}
+	return kb.addr.Sub(ka.addr).Rsh(128 - uint(ka.prefix)), nil
+}
+
+// IndexOf returns the position of the address within the network,
+// the network address has index zero.
+// ErrInvalidIP is returned if the network does not contain the address.
+func IndexOf(network *net.IPNet, addr net.IP) (Uint128, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return Uint128{}, err
+	}
+	ip, ipv6, err := ipKey(addr)
+	if err != nil {
+		return Uint128{}, err
+	}
+	if v6 != ipv6 || !ip.subnetOf(key) {
+		return Uint128{}, fmt.Errorf("%w: %s is out of %s", ErrInvalidIP, addr, network)
+	}
+
+	return ip.addr.Sub(key.addr).Rsh(128 - uint(ip.prefix)), nil
+}
+
+// AddressAt returns the n-th address of the network, it is the reverse of IndexOf.
+// ErrOverflow is returned if the network is too small.
+func AddressAt(network *net.IPNet, n Uint128) (net.IP, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return nil, err
+	}
+
+	bits := 8 * net.IPv4len
+	if v6 {
+		bits = 8 * net.IPv6len
+	}
+	if n.BitLen() > bits-int(key.prefix) {
+		return nil, fmt.Errorf("%w: %s has no address #%s", ErrOverflow, network, n)
+	}
+
+	key = ip6Net{addr: key.addr.Add(n.Lsh(128 - uint(bits))), prefix: uint8(bits)}
+	return keyNet(key, v6).IP, nil
+}
+
+// ipKey converts the address into the host network key, see tableKey().
+func ipKey(addr net.IP) (ip6Net, bool, error) {
+	bits := 8 * net.IPv6len
+	if addr.To4() != nil {
+		bits = 8 * net.IPv4len
+	}
+	key, v6, err := tableKey(&net.IPNet{IP: addr, Mask: net.CIDRMask(bits, bits)})
+	if err != nil {
+		return key, v6, ErrInvalidIP
+	}
+	return key, v6, nil
+}
+
+// add moves the network key offset positions forward
+// checking for overflow, see tableKey().
+func (n ip6Net) add(offset Uint128) (ip6Net, error) {
+	if offset.BitLen() > int(n.prefix) {
+		return n, ErrOverflow
+	}
+	sum, carry := u128.Add(n.addr, offset.Lsh(128-uint(n.prefix)), 0)
+	if carry != 0 {
+		return n, ErrOverflow
+	}
+	return ip6Net{addr: sum, prefix: n.prefix}, nil
+}
+
+// sub moves the network key offset positions backward
+// checking for underflow, see tableKey().
+func (n ip6Net) sub(offset Uint128) (ip6Net, error) {
+	if offset.BitLen() > int(n.prefix) {
+		return n, ErrOverflow
+	}
+	diff, borrow := u128.Sub(n.addr, offset.Lsh(128-uint(n.prefix)), 0)
+	if borrow != 0 {
+		return n, ErrOverflow
+	}
+	return ip6Net{addr: diff, prefix: n.prefix}, nil
+}
@@ -0,0 +1,165 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleIndexOf is an example of address positions in a large network
+func ExampleIndexOf() {
+	network := cidr("2001:db8::/48")
+	n, _ := ipx.IndexOf(network, net.ParseIP("2001:db8:0:1::5"))
+	fmt.Println(n)
+	ip, _ := ipx.AddressAt(network, n.Add64(1))
+	fmt.Println(ip)
+	// Output:
+	// 18446744073709551621
+	// 2001:db8:0:1::6
+}
+
+// TestAddIP unit tests for AddIP, SubIP and StepIP
+func TestAddIP(tt *testing.T) {
+	tt.Run("ipv4", func(t *testing.T) {
+		ip, err := ipx.AddIP(net.ParseIP("10.0.0.255"), ipx.Uint128{Lo: 1})
+		require.NoError(t, err)
+		assert.Equal(t, net.ParseIP("10.0.1.0").To4(), ip)
+
+		ip, err = ipx.StepIP(net.ParseIP("255.255.255.254"), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "255.255.255.255", ip.String())
+
+		_, err = ipx.StepIP(net.ParseIP("255.255.255.255"), 1)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.StepIP(net.ParseIP("0.0.0.0"), -1)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.AddIP(net.ParseIP("0.0.0.0"), ipx.Uint128{Lo: 1 << 32})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+
+		ip, err = ipx.SubIP(net.ParseIP("255.255.255.255"), ipx.Uint128{Lo: 1<<32 - 1})
+		require.NoError(t, err)
+		assert.Equal(t, "0.0.0.0", ip.String())
+	})
+
+	tt.Run("ipv6", func(t *testing.T) {
+		ip, err := ipx.AddIP(net.ParseIP("2001:db8::"), ipx.Uint128{Hi: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8:0:1::", ip.String())
+
+		ip, err = ipx.StepIP(net.ParseIP("2001:db8::"), -1)
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db7:ffff:ffff:ffff:ffff:ffff:ffff", ip.String())
+
+		_, err = ipx.AddIP(net.ParseIP("ffff::"), ipx.Uint128{Hi: 1 << 48})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.SubIP(net.ParseIP("::1"), ipx.Uint128{Lo: 2})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		_, err := ipx.AddIP(nil, ipx.Uint128{})
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+	})
+}
+
+// TestAddNetwork unit tests for AddNetwork, SubNetwork and StepNetwork
+func TestAddNetwork(tt *testing.T) {
+	tt.Run("ipv4", func(t *testing.T) {
+		n, err := ipx.StepNetwork(cidr("10.0.0.0/24"), 256)
+		require.NoError(t, err)
+		assert.Equal(t, "10.1.0.0/24", n.String())
+
+		n, err = ipx.StepNetwork(cidr("10.0.0.0/24"), -1)
+		require.NoError(t, err)
+		assert.Equal(t, "9.255.255.0/24", n.String())
+
+		_, err = ipx.StepNetwork(cidr("255.255.255.0/24"), 1)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.AddNetwork(cidr("0.0.0.0/24"), ipx.Uint128{Lo: 1 << 24})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+	})
+
+	tt.Run("ipv6", func(t *testing.T) {
+		n, err := ipx.AddNetwork(cidr("2001:db8::/48"), ipx.Uint128{Lo: 0xffff})
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8:ffff::/48", n.String())
+
+		n, err = ipx.SubNetwork(cidr("2001:db8::/64"), ipx.Uint128{Lo: 1 << 32})
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db7::/64", n.String())
+
+		_, err = ipx.SubNetwork(cidr("::/64"), ipx.Uint128{Lo: 1})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.StepNetwork(cidr("::/0"), 1)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		_, err := ipx.AddNetwork(nil, ipx.Uint128{})
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
+
+// TestDistance unit tests for Distance
+func TestDistance(t *testing.T) {
+	d, err := ipx.Distance(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, ipx.Uint128{Lo: 255}, d)
+
+	d, err = ipx.Distance(net.ParseIP("255.255.255.255"), net.ParseIP("0.0.0.0"))
+	require.NoError(t, err)
+	assert.Equal(t, ipx.Uint128{Lo: 1<<32 - 1}, d)
+
+	d, err = ipx.Distance(net.ParseIP("::"), net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"))
+	require.NoError(t, err)
+	assert.Equal(t, ipx.Uint128{Hi: maxUint64, Lo: maxUint64}, d)
+
+	_, err = ipx.Distance(net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::"))
+	assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+	_, err = ipx.Distance(nil, net.ParseIP("2001:db8::"))
+	assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+}
+
+// TestIndexOf unit tests for IndexOf and AddressAt
+func TestIndexOf(tt *testing.T) {
+	tt.Run("ipv4", func(t *testing.T) {
+		n, err := ipx.IndexOf(cidr("10.0.0.0/16"), net.ParseIP("10.0.2.3"))
+		require.NoError(t, err)
+		assert.Equal(t, ipx.Uint128{Lo: 515}, n)
+
+		ip, err := ipx.AddressAt(cidr("10.0.0.0/16"), n)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.2.3", ip.String())
+
+		ip, err = ipx.AddressAt(cidr("10.0.0.0/16"), ipx.Uint128{Lo: 1<<16 - 1})
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.255.255", ip.String())
+
+		_, err = ipx.AddressAt(cidr("10.0.0.0/16"), ipx.Uint128{Lo: 1 << 16})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.IndexOf(cidr("10.0.0.0/16"), net.ParseIP("10.1.0.0"))
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+		_, err = ipx.IndexOf(cidr("10.0.0.0/16"), net.ParseIP("2001:db8::"))
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+	})
+
+	tt.Run("ipv6", func(t *testing.T) {
+		last := net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+		n, err := ipx.IndexOf(cidr("::/0"), last)
+		require.NoError(t, err)
+		assert.Equal(t, ipx.Uint128{Hi: maxUint64, Lo: maxUint64}, n)
+
+		ip, err := ipx.AddressAt(cidr("::/0"), n)
+		require.NoError(t, err)
+		assert.Equal(t, last, ip)
+
+		_, err = ipx.AddressAt(cidr("2001:db8::/128"), ipx.Uint128{Lo: 1})
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.AddressAt(nil, ipx.Uint128{})
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
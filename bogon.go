@@ -0,0 +1,58 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+)
+
+// Bogons builds the full-bogon prefix lists for IPv4 and IPv6.
+//
+// The lists contain all special-purpose addresses which are not globally
+// reachable (see IsGlobal), multicast addresses and unallocated address
+// space provided by caller. Announced networks are removed from the lists.
+//
+// The zero value is ready to use and produces martians only.
+type Bogons struct {
+	unallocated []*net.IPNet
+	announced   []*net.IPNet
+}
+
+// AddUnallocated adds networks of unallocated address space to the lists.
+func (b *Bogons) AddUnallocated(networks ...*net.IPNet) *Bogons {
+	b.unallocated = append(b.unallocated, networks...)
+	return b
+}
+
+// Exclude removes the networks from the lists, like ExcludeAll() does.
+// Usually these are our own announced prefixes.
+func (b *Bogons) Exclude(networks ...*net.IPNet) *Bogons {
+	b.announced = append(b.announced, networks...)
+	return b
+}
+
+// Build returns collapsed IPv4 and IPv6 bogon lists.
+//
+// The IPv4-mapped block `::ffff:0:0/96` is not in the IPv6 list: *net.IPNet
+// of that block is treated as IPv4 `0.0.0.0/0` by this package and most consumers.
+// Callers generating IPv6 filters should add the block in their own notation.
+func (b *Bogons) Build() (v4, v6 []*net.IPNet, err error) {
+	unallocated, err := NewIPSet(b.unallocated...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unallocated", err)
+	}
+	announced, err := NewIPSet(b.announced...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: announced", err)
+	}
+	multicast, err := NewIPSet(specialMulticast...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := specialNonGlobal.
+		Union(multicast).
+		Union(unallocated).
+		Difference(announced)
+
+	return out.spans(false).networks(false), out.spans(true).networks(true), nil
+}
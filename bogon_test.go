@@ -0,0 +1,96 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleBogons is an example of bogon list building
+func ExampleBogons() {
+	v4, _, _ := new(ipx.Bogons).
+		Exclude(cidr("10.0.0.0/9")).
+		Build()
+	fmt.Println(v4[:3])
+	// Output:
+	// [0.0.0.0/8 10.128.0.0/9 100.64.0.0/10]
+}
+
+// netStrings converts the networks into strings.
+func netStrings(networks []*net.IPNet) []string {
+	var out []string
+	for _, n := range networks {
+		out = append(out, n.String())
+	}
+	return out
+}
+
+// TestBogons unit tests for Bogons
+func TestBogons(tt *testing.T) {
+	tt.Run("martians", func(t *testing.T) {
+		v4, v6, err := new(ipx.Bogons).Build()
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"0.0.0.0/8",
+			"10.0.0.0/8",
+			"100.64.0.0/10",
+			"127.0.0.0/8",
+			"169.254.0.0/16",
+			"172.16.0.0/12",
+			"192.0.0.0/29",
+			"192.0.0.8/32",
+			"192.0.0.11/32",
+			"192.0.0.12/30",
+			"192.0.0.16/28",
+			"192.0.0.32/27",
+			"192.0.0.64/26",
+			"192.0.0.128/25",
+			"192.0.2.0/24",
+			"192.168.0.0/16",
+			"198.18.0.0/15",
+			"198.51.100.0/24",
+			"203.0.113.0/24",
+			"224.0.0.0/3",
+		}, netStrings(v4))
+		assert.Contains(t, netStrings(v6), "2001:db8::/32")
+		assert.Contains(t, netStrings(v6), "fc00::/7")
+		assert.Contains(t, netStrings(v6), "ff00::/8")
+
+		// the IPv4-mapped block would be read as IPv4 `0.0.0.0/0`
+		for _, n := range v6 {
+			assert.Nil(t, n.IP.To4(), n)
+		}
+		lpm := new(ipx.Table[bool])
+		for _, n := range v6 {
+			require.NoError(t, lpm.Insert(n, true))
+		}
+		_, _, found := lpm.LookupLPM(net.ParseIP("8.8.8.8"))
+		assert.False(t, found)
+		assert.NotContains(t, netStrings(v6), "2001:4:112::/48")
+	})
+
+	tt.Run("full", func(t *testing.T) {
+		v4, v6, err := new(ipx.Bogons).
+			AddUnallocated(cidr("2c0f:f000::/20"), cidr("2001:0:8000::/33"), cidr("2c0f:e000::/20")).
+			Exclude(cidr("2001:db8:1::/48")).
+			Build()
+		require.NoError(t, err)
+		assert.Len(t, v4, 20)
+		assert.Contains(t, netStrings(v6), "2c0f:e000::/19") // collapsed
+		assert.Contains(t, netStrings(v6), "2001::/32")      // within TEREDO
+		assert.Contains(t, netStrings(v6), "2001:db8::/48")
+		assert.Contains(t, netStrings(v6), "2001:db8:2::/47")
+		assert.NotContains(t, netStrings(v6), "2001:db8::/32")
+	})
+
+	tt.Run("bad", func(t *testing.T) {
+		_, _, err := new(ipx.Bogons).AddUnallocated(nil).Build()
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, _, err = new(ipx.Bogons).Exclude(&net.IPNet{}).Build()
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
@@ -6,22 +6,26 @@ import (
 )
 
 // Collapse combines subnets into their closest available parent.
+// Host bits of the subnets are ignored, bad subnets are skipped.
 func Collapse(toMerge []*net.IPNet) []*net.IPNet {
 	var (
 		four []ip4Net
 		six  []ip6Net
 	)
 	for _, ipN := range toMerge {
+		if ipN = canonical(ipN); ipN == nil {
+			continue
+		}
 		if ipN.IP.To4() != nil {
 			four = append(four, newIP4Net(ipN))
 			continue
 		}
 		six = append(six, newIP6Net(ipN))
 	}
-	return append(collapse4(four), collapse6(six)...)
+	return append(collapse4(four).asNets(), collapse6(six).asNets()...)
 }
 
-func collapse4(nets []ip4Net) []*net.IPNet {
+func collapse4(nets []ip4Net) ip4Nets {
 	if len(nets) == 0 {
 		return nil
 	}
@@ -52,20 +56,20 @@ func collapse4(nets []ip4Net) []*net.IPNet {
 	}
 	sort.Sort(merged)
 
-	result := []*net.IPNet{merged[0].asNet()}
+	result := merged[:1]
 	lastMask := merged[0].mask()
 	lastAddr := merged[0].addr
 	for _, m := range merged[1:] {
 		if lastAddr == m.addr&lastMask {
 			continue
 		}
-		result = append(result, m.asNet())
+		result = append(result, m)
 		lastMask, lastAddr = m.mask(), m.addr
 	}
 	return result
 }
 
-func collapse6(nets []ip6Net) []*net.IPNet {
+func collapse6(nets []ip6Net) ip6Nets {
 	if len(nets) == 0 {
 		return nil
 	}
@@ -96,14 +100,14 @@ func collapse6(nets []ip6Net) []*net.IPNet {
 	}
 	sort.Sort(merged)
 
-	result := []*net.IPNet{merged[0].asNet()}
+	result := merged[:1]
 	lastMask := merged[0].mask()
 	lastAddr := merged[0].addr
 	for _, m := range merged[1:] {
 		if lastAddr == m.addr.And(lastMask) {
 			continue
 		}
-		result = append(result, m.asNet())
+		result = append(result, m)
 		lastMask, lastAddr = m.mask(), m.addr
 	}
 	return result
@@ -116,7 +120,9 @@ type ip4Net struct {
 
 func newIP4Net(ipN *net.IPNet) ip4Net {
 	ones, _ := ipN.Mask.Size()
-	return ip4Net{load32(ipN.IP), uint8(ones)}
+	n := ip4Net{load32(ipN.IP), uint8(ones)}
+	n.addr &= n.mask() // ignore host bits
+	return n
 }
 
 func (n ip4Net) super() ip4Net {
@@ -160,6 +166,17 @@ func (n ip4Nets) Swap(i, j int) {
 	n[i], n[j] = n[j], n[i]
 }
 
+func (n ip4Nets) asNets() []*net.IPNet {
+	if len(n) == 0 {
+		return nil
+	}
+	out := make([]*net.IPNet, 0, len(n))
+	for _, m := range n {
+		out = append(out, m.asNet())
+	}
+	return out
+}
+
 type ip6Net struct {
 	addr   Uint128
 	prefix uint8
@@ -167,7 +184,9 @@ type ip6Net struct {
 
 func newIP6Net(ipN *net.IPNet) ip6Net {
 	ones, _ := ipN.Mask.Size()
-	return ip6Net{load128(ipN.IP), uint8(ones)}
+	n := ip6Net{load128(ipN.IP), uint8(ones)}
+	n.addr = n.addr.And(n.mask()) // ignore host bits
+	return n
 }
 
 func (n ip6Net) super() ip6Net {
@@ -211,3 +230,14 @@ func (n ip6Nets) Less(i, j int) bool {
 func (n ip6Nets) Swap(i, j int) {
 	n[i], n[j] = n[j], n[i]
 }
+
+func (n ip6Nets) asNets() []*net.IPNet {
+	if len(n) == 0 {
+		return nil
+	}
+	out := make([]*net.IPNet, 0, len(n))
+	for _, m := range n {
+		out = append(out, m.asNet())
+	}
+	return out
+}
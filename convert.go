@@ -0,0 +1,168 @@
+package ipx
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
+)
+
+// IPToUint32 converts the IPv4 address into integer.
+// The address should be 4 bytes long or 16 bytes IPv4-mapped IPv6 address.
+func IPToUint32(ip net.IP) (uint32, error) {
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return 0, ErrInvalidIP
+	}
+	v4 := ip.To4()
+	if v4 == nil {
+		return 0, ErrVersionMismatch
+	}
+	return load32(v4), nil
+}
+
+// Uint32ToIP converts the integer into 4 bytes IPv4 address.
+func Uint32ToIP(u uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	store32(u, ip)
+	return ip
+}
+
+// IPToUint128 converts the address into integer.
+// The address should be 4 or 16 bytes long.
+// IPv4 addresses are converted as IPv4-mapped IPv6 addresses.
+func IPToUint128(ip net.IP) (Uint128, error) {
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return Uint128{}, ErrInvalidIP
+	}
+	return load128(ip.To16()), nil
+}
+
+// Uint128ToIP converts the integer into 16 bytes IPv6 address.
+func Uint128ToIP(u Uint128) net.IP {
+	ip := make(net.IP, net.IPv6len)
+	store128(u, ip)
+	return ip
+}
+
+// IPToBig converts the address into big integer.
+// Unlike IPToUint128 the IPv4 addresses are converted as 32 bits integers.
+// The address should be 4 or 16 bytes long.
+func IPToBig(ip net.IP) (*big.Int, error) {
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return nil, ErrInvalidIP
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return new(big.Int).SetBytes(v4), nil
+	}
+	return new(big.Int).SetBytes(ip), nil
+}
+
+// BigToIP converts the big integer into IPv4 or IPv6 address.
+// ErrOverflow is returned if the integer is negative or too big.
+func BigToIP(i *big.Int, v6 bool) (net.IP, error) {
+	size, version := net.IPv4len, 4
+	if v6 {
+		size, version = net.IPv6len, 6
+	}
+	if i == nil || i.Sign() < 0 || i.BitLen() > 8*size {
+		return nil, fmt.Errorf("%w: %s does not fit IPv%d address", ErrOverflow, i, version)
+	}
+
+	ip := make(net.IP, size)
+	i.FillBytes(ip)
+	return ip, nil
+}
+
+// IPToDecimal converts the address into decimal integer string,
+// the same as IPToBig does.
+func IPToDecimal(ip net.IP) (string, error) {
+	i, err := IPToBig(ip)
+	if err != nil {
+		return "", err
+	}
+	return i.String(), nil
+}
+
+// IPToHex converts the address into "0x" prefixed hexadecimal integer string
+// of fixed width: 8 digits for IPv4 and 32 digits for IPv6.
+func IPToHex(ip net.IP) (string, error) {
+	i, err := IPToBig(ip)
+	if err != nil {
+		return "", err
+	}
+	if ip.To4() != nil {
+		return fmt.Sprintf("0x%08x", i), nil
+	}
+	return fmt.Sprintf("0x%032x", i), nil
+}
+
+// ParseIPInteger parses decimal or "0x" prefixed hexadecimal integer string
+// into IPv4 or IPv6 address, it is the reverse of IPToDecimal and IPToHex.
+func ParseIPInteger(s string, v6 bool) (net.IP, error) {
+	base, digits := 10, s
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		base, digits = 16, s[2:]
+	}
+	// SetString accepts sign and underscores we do not want
+	if digits == "" || strings.ContainsAny(digits, "+-_") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIP, s)
+	}
+	i, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIP, s)
+	}
+	return BigToIP(i, v6)
+}
+
+// NetworkToUint32 converts the IPv4 network into address and prefix length pair.
+// Host bits of the network are ignored.
+func NetworkToUint32(network *net.IPNet) (uint32, int, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return 0, 0, err
+	}
+	if v6 {
+		return 0, 0, ErrVersionMismatch
+	}
+	n := key.narrow()
+	return n.addr, int(n.prefix), nil
+}
+
+// Uint32ToNetwork converts address and prefix length pair into IPv4 network.
+// Host bits of the address are ignored.
+func Uint32ToNetwork(addr uint32, prefixLen int) (*net.IPNet, error) {
+	if prefixLen < 0 || prefixLen > 8*net.IPv4len {
+		return nil, fmt.Errorf("%w: bad prefix length %d", ErrInvalidNetwork, prefixLen)
+	}
+	n := ip4Net{addr: addr, prefix: uint8(prefixLen)}
+	n.addr &= n.mask()
+	return n.asNet(), nil
+}
+
+// NetworkToUint128 converts the network into address and prefix length pair.
+// IPv4 networks are converted as IPv4-mapped IPv6 networks.
+// Host bits of the network are ignored.
+func NetworkToUint128(network *net.IPNet) (Uint128, int, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return Uint128{}, 0, err
+	}
+	if !v6 {
+		n := key.narrow()
+		return u128.From64(0xffff<<32 | uint64(n.addr)), int(n.prefix) + 96, nil
+	}
+	return key.addr, int(key.prefix), nil
+}
+
+// Uint128ToNetwork converts address and prefix length pair into IPv6 network.
+// Host bits of the address are ignored.
+func Uint128ToNetwork(addr Uint128, prefixLen int) (*net.IPNet, error) {
+	if prefixLen < 0 || prefixLen > 8*net.IPv6len {
+		return nil, fmt.Errorf("%w: bad prefix length %d", ErrInvalidNetwork, prefixLen)
+	}
+	n := ip6Net{addr: addr, prefix: uint8(prefixLen)}
+	n.addr = n.addr.And(n.mask())
+	return n.asNet(), nil
+}
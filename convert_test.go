@@ -0,0 +1,156 @@
+package ipx_test
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleIPToHex is an example of integer conversions
+func ExampleIPToHex() {
+	hex, _ := ipx.IPToHex(net.ParseIP("10.0.0.1"))
+	ip, _ := ipx.ParseIPInteger(hex, false)
+	dec, _ := ipx.IPToDecimal(ip)
+	fmt.Println(hex, ip, dec)
+	// Output:
+	// 0x0a000001 10.0.0.1 167772161
+}
+
+// TestIPToUint32 unit tests for IPv4 integer conversions
+func TestIPToUint32(t *testing.T) {
+	u, err := ipx.IPToUint32(net.ParseIP("10.0.0.1"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x0a000001), u)
+	u, err = ipx.IPToUint32(net.IP{192, 168, 0, 1})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0xc0a80001), u)
+	assert.Equal(t, net.IP{192, 168, 0, 1}, ipx.Uint32ToIP(u))
+
+	_, err = ipx.IPToUint32(net.ParseIP("2001:db8::"))
+	assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+	_, err = ipx.IPToUint32(net.IP{0, 10, 0, 0, 1}) // no "last 4 bytes" tolerance
+	assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+	_, err = ipx.IPToUint32(nil)
+	assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+}
+
+// TestIPToUint128 unit tests for 128 bits integer conversions
+func TestIPToUint128(t *testing.T) {
+	u, err := ipx.IPToUint128(net.ParseIP("2001:db8::1"))
+	require.NoError(t, err)
+	assert.Equal(t, ipx.Uint128{Hi: 0x20010db800000000, Lo: 1}, u)
+	assert.Equal(t, net.ParseIP("2001:db8::1"), ipx.Uint128ToIP(u))
+
+	u, err = ipx.IPToUint128(net.IP{10, 0, 0, 1})
+	require.NoError(t, err)
+	assert.Equal(t, ipx.Uint128{Lo: 0xffff0a000001}, u)
+
+	_, err = ipx.IPToUint128(net.IP{1, 2, 3})
+	assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+}
+
+// TestIPToBig unit tests for big integer and string conversions
+func TestIPToBig(tt *testing.T) {
+	tt.Run("big", func(t *testing.T) {
+		i, err := ipx.IPToBig(net.ParseIP("10.0.0.1"))
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(0x0a000001), i)
+
+		ip, err := ipx.BigToIP(i, false)
+		require.NoError(t, err)
+		assert.Equal(t, net.IP{10, 0, 0, 1}, ip)
+		ip, err = ipx.BigToIP(i, true)
+		require.NoError(t, err)
+		assert.Equal(t, "::a00:1", ip.String())
+
+		_, err = ipx.BigToIP(big.NewInt(1<<32), false)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.BigToIP(big.NewInt(-1), true)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.BigToIP(nil, true)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.IPToBig(net.IP{1})
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+	})
+
+	tt.Run("strings", func(t *testing.T) {
+		last := net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")
+		dec, err := ipx.IPToDecimal(last)
+		require.NoError(t, err)
+		assert.Equal(t, "340282366920938463463374607431768211455", dec)
+		hex, err := ipx.IPToHex(net.ParseIP("2001:db8::1"))
+		require.NoError(t, err)
+		assert.Equal(t, "0x20010db8000000000000000000000001", hex)
+
+		for s, expected := range map[string]string{
+			"340282366920938463463374607431768211455": "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+			"0x20010db8000000000000000000000001":      "2001:db8::1",
+			"0X1":                                     "::1",
+			"0":                                       "::",
+		} {
+			ip, err := ipx.ParseIPInteger(s, true)
+			if assert.NoError(t, err, s) {
+				assert.Equal(t, expected, ip.String(), s)
+			}
+		}
+
+		for _, s := range []string{"", "0x", "-1", "+1", "1_000", "0xfoo", "1.2.3.4"} {
+			_, err := ipx.ParseIPInteger(s, false)
+			assert.ErrorIs(t, err, ipx.ErrInvalidIP, s)
+		}
+		_, err = ipx.ParseIPInteger("4294967296", false)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.IPToDecimal(nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+		_, err = ipx.IPToHex(nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+	})
+}
+
+// TestNetworkToUint unit tests for network integer conversions
+func TestNetworkToUint(tt *testing.T) {
+	tt.Run("ipv4", func(t *testing.T) {
+		addr, prefix, err := ipx.NetworkToUint32(cidr("10.1.2.3/16"))
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0x0a010000), addr)
+		assert.Equal(t, 16, prefix)
+
+		n, err := ipx.Uint32ToNetwork(0x0a010203, 16)
+		require.NoError(t, err)
+		assert.Equal(t, "10.1.0.0/16", n.String())
+
+		_, _, err = ipx.NetworkToUint32(cidr("2001:db8::/32"))
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+		_, err = ipx.Uint32ToNetwork(0, 33)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+
+	tt.Run("ipv6", func(t *testing.T) {
+		addr, prefix, err := ipx.NetworkToUint128(cidr("2001:db8::1/32"))
+		require.NoError(t, err)
+		assert.Equal(t, ipx.Uint128{Hi: 0x20010db800000000}, addr)
+		assert.Equal(t, 32, prefix)
+
+		addr, prefix, err = ipx.NetworkToUint128(cidr("10.0.0.0/8"))
+		require.NoError(t, err)
+		assert.Equal(t, ipx.Uint128{Lo: 0xffff0a000000}, addr)
+		assert.Equal(t, 104, prefix)
+
+		n, err := ipx.Uint128ToNetwork(addr, prefix)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/8", n.String()) // IPv4-mapped
+		n, err = ipx.Uint128ToNetwork(ipx.Uint128{Hi: 0x20010db8ffffffff}, 32)
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8::/32", n.String())
+
+		_, err = ipx.Uint128ToNetwork(addr, -1)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, _, err = ipx.NetworkToUint128(nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
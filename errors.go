@@ -14,4 +14,52 @@ var (
 	// ErrInvalidNetwork is bad IP network error.
 	// When we pass bad or empty IP network.
 	ErrInvalidNetwork = errors.New("invalid IP network")
+
+	// ErrInvalidRange is bad IP range error.
+	// When we pass bad IP range notation.
+	ErrInvalidRange = errors.New("invalid IP range")
+
+	// ErrInvalidTarget is bad scan target error.
+	// When we pass bad target specification.
+	ErrInvalidTarget = errors.New("invalid target")
+
+	// ErrOverflow is IP arithmetic overflow error.
+	// When result does not fit into IP address space or integer type.
+	ErrOverflow = errors.New("IP arithmetic overflow")
+
+	// ErrInvalidIterState is bad iterator state error.
+	// When we resume an iterator from corrupted or unsupported state.
+	ErrInvalidIterState = errors.New("invalid iterator state")
+
+	// ErrInvalidShard is bad iterator shard error.
+	// When shard index is not less than the number of shards.
+	ErrInvalidShard = errors.New("invalid shard")
+
+	// ErrExhausted is no free space error.
+	// When allocator has no free block of requested size.
+	ErrExhausted = errors.New("address space exhausted")
+
+	// ErrAllocated is already allocated error.
+	// When we request a block overlapping allocated ones.
+	ErrAllocated = errors.New("already allocated")
+
+	// ErrNotAllocated is not allocated error.
+	// When we release a block that was not allocated.
+	ErrNotAllocated = errors.New("not allocated")
+
+	// ErrInvalidAllocatorState is bad allocator state error.
+	// When we restore an allocator from corrupted or unsupported state.
+	ErrInvalidAllocatorState = errors.New("invalid allocator state")
+
+	// ErrInvalidRequirement is bad planner requirement error.
+	// When a VLSM requirement cannot be parsed or has no valid size.
+	ErrInvalidRequirement = errors.New("invalid requirement")
+
+	// ErrInvalidMask is bad network mask error.
+	// When a mask cannot be parsed or is not contiguous.
+	ErrInvalidMask = errors.New("invalid network mask")
+
+	// ErrInvalidPTR is bad reverse DNS name error.
+	// When a name is not a valid in-addr.arpa or ip6.arpa name.
+	ErrInvalidPTR = errors.New("invalid reverse PTR name")
 )
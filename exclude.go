@@ -1,57 +1,84 @@
 package ipx
 
-import "net"
+import (
+	"fmt"
+	"net"
+)
 
 // Exclude returns a list of networks representing the address block when `b` is removed from `a`.
+// Host bits of the networks are ignored.
 func Exclude(a, b *net.IPNet) []*net.IPNet {
+	if a = canonical(a); a == nil {
+		return nil // no network, no addresses
+	}
+	if b = canonical(b); b == nil {
+		return []*net.IPNet{a} // nothing to remove
+	}
+
 	four := a.IP.To4() != nil
 	if four != (b.IP.To4() != nil) || !IsSubnet(a, b) {
 		return []*net.IPNet{a}
 	}
 	if four {
-		return exclude4(newIP4Net(a), newIP4Net(b))
+		return exclude4(newIP4Net(a), newIP4Net(b)).asNets()
+	}
+	return exclude6(newIP6Net(a), newIP6Net(b)).asNets()
+}
+
+// ExcludeAll returns a collapsed list of networks representing the address blocks
+// of `parents` when all the `holes` are removed. Holes may partially overlap
+// or span several parents. IPv4 and IPv6 networks can be mixed in both lists.
+func ExcludeAll(parents, holes []*net.IPNet) ([]*net.IPNet, error) {
+	a, err := NewIPSet(parents...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parents", err)
 	}
-	return exclude6(newIP6Net(a), newIP6Net(b))
+	b, err := NewIPSet(holes...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: holes", err)
+	}
+
+	return a.Difference(b).Prefixes(), nil
 }
 
-func exclude4(a, b ip4Net) []*net.IPNet {
-	subs := make([]*net.IPNet, 0, a.prefix-b.prefix)
+func exclude4(a, b ip4Net) ip4Nets {
+	subs := make(ip4Nets, 0, b.prefix-a.prefix)
 
 	s1, s2 := a.subnets()
 	for s1 != b && s2 != b {
 		if b.subnetOf(s1) {
-			subs = append(subs, s2.asNet())
+			subs = append(subs, s2)
 			s1, s2 = s1.subnets()
 			continue
 		}
-		subs = append(subs, s1.asNet())
+		subs = append(subs, s1)
 		s1, s2 = s2.subnets()
 	}
 	if s1 == b {
-		subs = append(subs, s2.asNet())
+		subs = append(subs, s2)
 	} else {
-		subs = append(subs, s1.asNet())
+		subs = append(subs, s1)
 	}
 	return subs
 }
 
-func exclude6(a, b ip6Net) []*net.IPNet {
-	subs := make([]*net.IPNet, 0, a.prefix-b.prefix)
+func exclude6(a, b ip6Net) ip6Nets {
+	subs := make(ip6Nets, 0, b.prefix-a.prefix)
 
 	s1, s2 := a.subnets()
 	for s1 != b && s2 != b {
 		if b.subnetOf(s1) {
-			subs = append(subs, s2.asNet())
+			subs = append(subs, s2)
 			s1, s2 = s1.subnets()
 			continue
 		}
-		subs = append(subs, s1.asNet())
+		subs = append(subs, s1)
 		s1, s2 = s2.subnets()
 	}
 	if s1 == b {
-		subs = append(subs, s2.asNet())
+		subs = append(subs, s2)
 	} else {
-		subs = append(subs, s1.asNet())
+		subs = append(subs, s1)
 	}
 	return subs
 }
@@ -3,6 +3,9 @@ package ipx_test
 import (
 	"fmt"
 	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
 	"testing"
 )
 
@@ -67,6 +70,59 @@ func TestExclude(t *testing.T) {
 	}
 }
 
+// ExampleExcludeAll is an example of ExcludeAll
+func ExampleExcludeAll() {
+	fmt.Println(
+		ipx.ExcludeAll(
+			[]*net.IPNet{cidr("10.1.0.0/24"), cidr("10.1.1.0/24")},
+			[]*net.IPNet{cidr("10.1.0.128/25"), cidr("10.1.1.0/25")},
+		),
+	)
+	// Output:
+	// [10.1.0.0/25 10.1.1.128/25] <nil>
+}
+
+// TestExcludeAll unit tests for ExcludeAll
+func TestExcludeAll(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		parents  []string
+		holes    []string
+		expected []string
+	}{
+		{"empty", nil, nil, nil},
+		{"no holes", []string{"10.1.1.0/25", "10.1.1.128/25"}, nil, []string{"10.1.1.0/24"}},
+		{"disjoint", []string{"10.1.1.0/24"}, []string{"10.0.1.0/26"}, []string{"10.1.1.0/24"}},
+		{"mixed versions", []string{"10.1.1.0/24", "2001:db8::/126"}, []string{"2001:db8::1/128"},
+			[]string{"10.1.1.0/24", "2001:db8::/128", "2001:db8::2/127"}},
+		{"many holes", []string{"10.1.1.0/24"}, []string{"10.1.1.0/26", "10.1.1.5/32", "10.1.1.192/26"},
+			[]string{"10.1.1.64/26", "10.1.1.128/26"}},
+		{"hole spans parents", []string{"10.1.0.0/24", "10.1.1.0/24", "10.1.3.0/24"}, []string{"10.1.0.128/25", "10.1.1.0/25", "10.1.2.0/23"},
+			[]string{"10.1.0.0/25", "10.1.1.128/25"}},
+		{"hole covers parent", []string{"10.1.1.0/24", "192.168.0.0/16"}, []string{"10.0.0.0/8"}, []string{"192.168.0.0/16"}},
+		{"overlapping parents", []string{"10.1.0.0/16", "10.1.1.0/24"}, []string{"10.1.0.0/17"}, []string{"10.1.128.0/17"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var parents, holes []*net.IPNet
+			for _, s := range c.parents {
+				parents = append(parents, cidr(s))
+			}
+			for _, s := range c.holes {
+				holes = append(holes, cidr(s))
+			}
+
+			got, err := ipx.ExcludeAll(parents, holes)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, Networks(got).Strings())
+		})
+	}
+
+	_, err := ipx.ExcludeAll([]*net.IPNet{nil}, nil)
+	assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	_, err = ipx.ExcludeAll(nil, []*net.IPNet{{IP: net.IPv4zero}})
+	assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+}
+
 func BenchmarkExclude(b *testing.B) {
 	type bench struct {
 		a, b string
@@ -105,3 +161,19 @@ func BenchmarkExclude(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkExcludeAll(b *testing.B) {
+	var holes []*net.IPNet
+	for i := 0; i < 50000; i++ {
+		holes = append(holes, &net.IPNet{
+			IP:   net.IPv4(10, byte(i>>8), byte(i), 0).To4(),
+			Mask: net.CIDRMask(26, 32),
+		})
+	}
+	parents := []*net.IPNet{cidr("0.0.0.0/0"), cidr("10.0.0.0/8")}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ipx.ExcludeAll(parents, holes)
+	}
+}
@@ -0,0 +1,109 @@
+package ipx
+
+import (
+	"math"
+	"net"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
+)
+
+// FreeSpaceReport describes free address space of a parent network.
+type FreeSpaceReport struct {
+	Parent *net.IPNet
+
+	// Free is the minimal sorted list of free networks.
+	Free []*net.IPNet
+
+	// FreeRanges is the sorted list of disjoint free IP ranges.
+	FreeRanges []Range
+
+	// Largest is the largest free network, the lowest one of the same size.
+	// It is nil if there is no free space.
+	Largest *net.IPNet
+
+	// FreeByPrefix is the number of free networks (see Free) per prefix length.
+	FreeByPrefix map[int]int
+
+	// Total, Used and Free address counts.
+	// The entire IPv6 address space is saturated to the maximum value.
+	TotalCount Uint128
+	UsedCount  Uint128
+	FreeCount  Uint128
+
+	// Fragmentation is the part of free addresses outside the largest
+	// free network: from 0 (not fragmented at all) to nearly 1.
+	Fragmentation float64
+}
+
+// FreeSpace returns the report of free address space of the parent network
+// when all the used networks are removed from it. Used networks may overlap
+// each other or be partially (or entirely) outside of the parent.
+func FreeSpace(parent *net.IPNet, used []*net.IPNet) (*FreeSpaceReport, error) {
+	p, err := NewIPSet(parent)
+	if err != nil {
+		return nil, err
+	}
+	u, err := NewIPSet(used...)
+	if err != nil {
+		return nil, err
+	}
+
+	free := p.Difference(u)
+	r := &FreeSpaceReport{
+		Parent:       parent,
+		Free:         free.Prefixes(),
+		FreeRanges:   free.Ranges(),
+		FreeByPrefix: make(map[int]int),
+		TotalCount:   p.size(),
+		UsedCount:    p.Intersect(u).size(),
+		FreeCount:    free.size(),
+	}
+
+	largest := -1
+	for _, n := range r.Free {
+		ones, _ := n.Mask.Size()
+		r.FreeByPrefix[ones]++
+		if r.Largest == nil || ones < largest {
+			r.Largest, largest = n, ones
+		}
+	}
+
+	if r.Largest != nil {
+		span, _, _ := netSpan(r.Largest)
+		size := ipSpans{span}.size()
+		r.Fragmentation = 1 - float128(size)/float128(r.FreeCount)
+	}
+
+	return r, nil
+}
+
+// size returns the number of addresses in the set saturated to the maximum value.
+func (s *IPSet) size() Uint128 {
+	four, six := s.spans(false).size(), s.spans(true).size()
+	if sum := four.Add(six); sum.Cmp(six) >= 0 {
+		return sum
+	}
+	return u128.Max() // saturated
+}
+
+// size returns the number of addresses of all spans saturated to the maximum value.
+func (s ipSpans) size() Uint128 {
+	var out Uint128
+	for _, span := range s {
+		d := span.last.Sub(span.first)
+		if d.Equals(u128.Max()) {
+			return d // saturated
+		}
+		if next := out.Add(d.Add64(1)); next.Cmp(out) > 0 {
+			out = next
+		} else {
+			return u128.Max() // saturated
+		}
+	}
+	return out
+}
+
+// float128 converts 128 bits integer to float.
+func float128(u Uint128) float64 {
+	return float64(u.Hi)*math.Exp2(64) + float64(u.Lo)
+}
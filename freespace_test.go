@@ -0,0 +1,86 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleFreeSpace is an example of free space report
+func ExampleFreeSpace() {
+	r, _ := ipx.FreeSpace(cidr("10.0.0.0/24"), []*net.IPNet{
+		cidr("10.0.0.0/26"),
+		cidr("10.0.0.128/27"),
+	})
+	fmt.Println(r.Free, r.Largest)
+	fmt.Println(r.TotalCount.Lo, r.UsedCount.Lo, r.FreeCount.Lo, r.Fragmentation)
+	// Output:
+	// [10.0.0.64/26 10.0.0.160/27 10.0.0.192/26] 10.0.0.64/26
+	// 256 96 160 0.6
+}
+
+// TestFreeSpace unit tests for FreeSpace
+func TestFreeSpace(tt *testing.T) {
+	tt.Run("ipv4", func(t *testing.T) {
+		r, err := ipx.FreeSpace(cidr("10.0.0.0/24"), []*net.IPNet{
+			cidr("10.0.0.8/29"),
+			cidr("10.0.0.8/30"),   // overlapped
+			cidr("10.0.0.255/32"), // the last address
+			cidr("10.0.1.0/24"),   // outside
+			cidr("2001:db8::/32"), // another version
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"10.0.0.0/29",
+			"10.0.0.16/28",
+			"10.0.0.32/27",
+			"10.0.0.64/26",
+			"10.0.0.128/26",
+			"10.0.0.192/27",
+			"10.0.0.224/28",
+			"10.0.0.240/29",
+			"10.0.0.248/30",
+			"10.0.0.252/31",
+			"10.0.0.254/32",
+		}, netStrings(r.Free))
+		require.Len(t, r.FreeRanges, 2)
+		assert.Equal(t, "10.0.0.0-10.0.0.7", r.FreeRanges[0].String())
+		assert.Equal(t, "10.0.0.16-10.0.0.254", r.FreeRanges[1].String())
+		assert.Equal(t, "10.0.0.64/26", r.Largest.String())
+		assert.Equal(t, map[int]int{26: 2, 27: 2, 28: 2, 29: 2, 30: 1, 31: 1, 32: 1}, r.FreeByPrefix)
+		assert.Equal(t, ipx.Uint128{Lo: 256}, r.TotalCount)
+		assert.Equal(t, ipx.Uint128{Lo: 9}, r.UsedCount)
+		assert.Equal(t, ipx.Uint128{Lo: 247}, r.FreeCount)
+		assert.InDelta(t, 1-64.0/247, r.Fragmentation, 1e-9)
+	})
+
+	tt.Run("full", func(t *testing.T) {
+		r, err := ipx.FreeSpace(cidr("2001:db8::/64"), []*net.IPNet{cidr("2001:db8::/48")})
+		require.NoError(t, err)
+		assert.Empty(t, r.Free)
+		assert.Nil(t, r.Largest)
+		assert.Equal(t, r.TotalCount, r.UsedCount)
+		assert.True(t, r.FreeCount.IsZero())
+		assert.Zero(t, r.Fragmentation)
+	})
+
+	tt.Run("empty", func(t *testing.T) {
+		r, err := ipx.FreeSpace(cidr("::/0"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"::/0"}, netStrings(r.Free))
+		assert.Equal(t, ipx.Uint128{Hi: maxUint64, Lo: maxUint64}, r.TotalCount)
+		assert.Equal(t, r.TotalCount, r.FreeCount)
+		assert.Zero(t, r.Fragmentation)
+	})
+
+	tt.Run("bad", func(t *testing.T) {
+		_, err := ipx.FreeSpace(nil, nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.FreeSpace(cidr("10.0.0.0/8"), []*net.IPNet{nil})
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
@@ -1,8 +1,14 @@
 module github.com/ns1/ipx/v2
 
-go 1.15
+go 1.18
 
 require (
 	github.com/Pilatuz/bigx/v2 v2.0.0-alpha
 	github.com/stretchr/testify v1.7.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
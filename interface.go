@@ -0,0 +1,175 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Interface is an IP address together with its network,
+// like `10.0.0.5/24` configured on a network interface.
+// Unlike *net.IPNet the host bits of the address are kept.
+//
+// Interface is encoded as text in `address/prefix` form.
+// The zero Interface has no address.
+type Interface struct {
+	ip   net.IP // 4 or 16 bytes
+	ones int
+}
+
+// NewInterface returns the interface of the address
+// and the network prefix length.
+func NewInterface(addr net.IP, prefixLen int) (Interface, error) {
+	ip := addr.To4()
+	if ip == nil {
+		ip = addr.To16()
+	}
+	if ip == nil {
+		return Interface{}, ErrInvalidIP
+	}
+	if prefixLen < 0 || prefixLen > 8*len(ip) {
+		return Interface{}, fmt.Errorf("%w: bad prefix length %d", ErrInvalidNetwork, prefixLen)
+	}
+
+	return Interface{
+		ip:   append(net.IP(nil), ip...), // copy
+		ones: prefixLen,
+	}, nil
+}
+
+// ParseInterface parses the interface in `10.0.0.5/24` or `2001:db8::5/64` form.
+// A single address without prefix length is considered as a host network.
+func ParseInterface(s string) (Interface, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return Interface{}, fmt.Errorf("%w: %q", ErrInvalidIP, s)
+		}
+		if v4 := ip.To4(); v4 != nil {
+			ip = v4
+		}
+		return Interface{ip: ip, ones: 8 * len(ip)}, nil
+	}
+
+	ip, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return Interface{}, fmt.Errorf("%w: %q", ErrInvalidNetwork, s)
+	}
+	ones, _ := network.Mask.Size()
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	return Interface{ip: ip, ones: ones}, nil
+}
+
+// Address returns the address of the interface.
+func (i Interface) Address() net.IP {
+	return i.ip
+}
+
+// PrefixLen returns the network prefix length of the interface.
+func (i Interface) PrefixLen() int {
+	return i.ones
+}
+
+// Network returns the network of the interface, host bits are cleared.
+// Returns nil for the zero Interface.
+func (i Interface) Network() *net.IPNet {
+	if i.ip == nil {
+		return nil
+	}
+	mask := net.CIDRMask(i.ones, 8*len(i.ip))
+	return &net.IPNet{
+		IP:   i.ip.Mask(mask),
+		Mask: mask,
+	}
+}
+
+// Hosts returns all the hosts of the interface network except the interface address.
+// Network and broadcast addresses are not hosts, unless the network has
+// only one or two addresses (i.e. /31 or /32, see RFC 3021).
+func (i Interface) Hosts() *RangeSetIter {
+	if i.ip == nil {
+		return new(RangeSetIter)
+	}
+
+	first, last := RangeFromNetwork(i.Network())
+	if i.ones < 8*len(i.ip)-1 {
+		first, last = NextIP(first, +1), NextIP(last, -1)
+	}
+
+	set, err := NewRangeSet(NewRange(first, last))
+	if err != nil {
+		return new(RangeSetIter)
+	}
+	_ = set.Remove(NewRange(i.ip, i.ip))
+	return set.Addresses()
+}
+
+// String returns the interface in `address/prefix` form.
+// Returns empty string for the zero Interface.
+func (i Interface) String() string {
+	if i.ip == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%d", i.ip, i.ones)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String().
+func (i Interface) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The interface is expected in a form accepted by ParseInterface().
+// Empty text is decoded as the zero Interface.
+func (i *Interface) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*i = Interface{}
+		return nil
+	}
+
+	out, err := ParseInterface(string(text))
+	if err != nil {
+		return err
+	}
+
+	*i = out
+	return nil
+}
+
+// Compare compares two interfaces by their networks first and addresses then.
+// IPv4 interfaces go before IPv6 interfaces. Returns:
+//   - `0` if a == b,
+//   - `-1` if a < b,
+//   - `+1` if a > b.
+func (i Interface) Compare(o Interface) int {
+	switch {
+	case len(i.ip) != len(o.ip):
+		if len(i.ip) < len(o.ip) {
+			return -1
+		}
+		return +1
+	case i.ip == nil:
+		return 0 // both are zero
+	}
+
+	a, b := i.Network(), o.Network()
+	if cmp := MustCompareIP(a.IP, b.IP); cmp != 0 {
+		return cmp
+	}
+	if i.ones != o.ones {
+		if i.ones < o.ones {
+			return -1
+		}
+		return +1
+	}
+	return MustCompareIP(i.ip, o.ip)
+}
+
+// Equal returns true if both interfaces have the same address and network.
+func (i Interface) Equal(o Interface) bool {
+	return i.Compare(o) == 0
+}
@@ -0,0 +1,136 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleParseInterface is an example of interface address
+func ExampleParseInterface() {
+	iface, _ := ipx.ParseInterface("10.0.0.5/24")
+	fmt.Println(iface, iface.Address(), iface.Network())
+	// Output:
+	// 10.0.0.5/24 10.0.0.5 10.0.0.0/24
+}
+
+// hostStrings collects all the rest addresses of the iterator.
+func hostStrings(iter *ipx.RangeSetIter) []string {
+	var out []string
+	for iter.Next() {
+		out = append(out, iter.IP().String())
+	}
+	return out
+}
+
+// TestParseInterface unit tests for ParseInterface
+func TestParseInterface(tt *testing.T) {
+	tt.Run("valid", func(t *testing.T) {
+		for _, c := range []struct {
+			s       string
+			addr    string
+			network string
+		}{
+			{"10.0.0.5/24", "10.0.0.5", "10.0.0.0/24"},
+			{" 10.0.0.5 ", "10.0.0.5", "10.0.0.5/32"},
+			{"2001:db8::5/64", "2001:db8::5", "2001:db8::/64"},
+			{"2001:db8::5", "2001:db8::5", "2001:db8::5/128"},
+		} {
+			iface, err := ipx.ParseInterface(c.s)
+			if assert.NoError(t, err, c.s) {
+				assert.Equal(t, c.addr, iface.Address().String(), c.s)
+				assert.Equal(t, c.network, iface.Network().String(), c.s)
+			}
+		}
+
+		iface, err := ipx.ParseInterface("10.0.0.5/24")
+		require.NoError(t, err)
+		assert.Len(t, iface.Address(), net.IPv4len)
+		assert.Equal(t, 24, iface.PrefixLen())
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		_, err := ipx.ParseInterface("10.0.0.5/33")
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.ParseInterface("foo")
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+		_, err = ipx.NewInterface(nil, 24)
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+		_, err = ipx.NewInterface(net.ParseIP("10.0.0.1"), 33)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
+
+// TestInterfaceHosts unit tests for Interface.Hosts
+func TestInterfaceHosts(t *testing.T) {
+	for s, expected := range map[string][]string{
+		"10.0.0.2/29":     {"10.0.0.1", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"},
+		"10.0.0.0/29":     {"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"},
+		"10.0.0.1/31":     {"10.0.0.0"},
+		"10.0.0.1/32":     nil,
+		"2001:db8::1/126": {"2001:db8::2"},
+		"2001:db8::/127":  {"2001:db8::1"},
+	} {
+		iface, err := ipx.ParseInterface(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, hostStrings(iface.Hosts()), s)
+	}
+	assert.False(t, ipx.Interface{}.Hosts().Next())
+}
+
+// TestInterfaceText unit tests for Interface text encoding
+func TestInterfaceText(t *testing.T) {
+	iface, err := ipx.NewInterface(net.ParseIP("2001:db8::5"), 64)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::5/64", iface.String())
+
+	data, err := json.Marshal(map[string]ipx.Interface{"eth0": iface, "lo": {}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"eth0":"2001:db8::5/64","lo":""}`, string(data))
+
+	var out map[string]ipx.Interface
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.True(t, iface.Equal(out["eth0"]))
+	assert.True(t, out["lo"].Equal(ipx.Interface{}))
+	assert.Nil(t, out["lo"].Network())
+
+	var bad ipx.Interface
+	assert.Error(t, bad.UnmarshalText([]byte("10.0.0.1/99")))
+}
+
+// TestInterfaceCompare unit tests for Interface.Compare
+func TestInterfaceCompare(t *testing.T) {
+	var ifaces []ipx.Interface
+	for _, s := range []string{
+		"2001:db8::1/64",
+		"10.0.0.5/24",
+		"10.0.0.5/16",
+		"10.0.0.1/24",
+		"9.0.0.1/8",
+	} {
+		iface, err := ipx.ParseInterface(s)
+		require.NoError(t, err)
+		ifaces = append(ifaces, iface)
+	}
+	ifaces = append(ifaces, ipx.Interface{})
+
+	sort.Slice(ifaces, func(i, j int) bool {
+		return ifaces[i].Compare(ifaces[j]) < 0
+	})
+
+	var got []string
+	for _, iface := range ifaces {
+		got = append(got, iface.String())
+	}
+	assert.Equal(t, []string{"", "9.0.0.1/8", "10.0.0.5/16", "10.0.0.1/24", "10.0.0.5/24", "2001:db8::1/64"}, got)
+
+	a, _ := ipx.ParseInterface("10.0.0.5/24")
+	b, _ := ipx.NewInterface(net.ParseIP("::ffff:10.0.0.5"), 24)
+	assert.Equal(t, 0, a.Compare(b))
+}
@@ -0,0 +1,184 @@
+package ipx
+
+import (
+	"net"
+)
+
+// IPSet is a set of IPv4 and IPv6 addresses.
+// Set operations always keep the set normalized, so
+// the set can be represented as a minimal sorted list
+// of networks or disjoint IP ranges.
+//
+// The zero value is an empty set ready to use.
+type IPSet struct {
+	v4 ipSpans
+	v6 ipSpans
+}
+
+// NewIPSet returns a set containing all the networks.
+func NewIPSet(networks ...*net.IPNet) (*IPSet, error) {
+	var four, six []ipSpan
+	for _, network := range networks {
+		span, v6, err := netSpan(network)
+		if err != nil {
+			return nil, err
+		}
+		if v6 {
+			six = append(six, span)
+		} else {
+			four = append(four, span)
+		}
+	}
+
+	return &IPSet{
+		v4: normalizeSpans(four),
+		v6: normalizeSpans(six),
+	}, nil
+}
+
+// AddNet adds all addresses of the network to the set.
+func (s *IPSet) AddNet(network *net.IPNet) error {
+	span, v6, err := netSpan(network)
+	if err != nil {
+		return err
+	}
+
+	s.add(span, v6)
+	return nil
+}
+
+// AddRange adds all addresses of the IP range to the set.
+func (s *IPSet) AddRange(r Range) error {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return err
+	}
+
+	s.add(span, v6)
+	return nil
+}
+
+// RemoveNet removes all addresses of the network from the set.
+func (s *IPSet) RemoveNet(network *net.IPNet) error {
+	span, v6, err := netSpan(network)
+	if err != nil {
+		return err
+	}
+
+	s.remove(span, v6)
+	return nil
+}
+
+// RemoveRange removes all addresses of the IP range from the set.
+func (s *IPSet) RemoveRange(r Range) error {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return err
+	}
+
+	s.remove(span, v6)
+	return nil
+}
+
+// Contains returns true if the set contains the IP address.
+func (s *IPSet) Contains(addr net.IP) bool {
+	u, v6, err := loadIP(addr)
+	if err != nil {
+		return false // bad address
+	}
+
+	return s.spans(v6).search(u) >= 0
+}
+
+// IsEmpty returns true if the set contains no addresses.
+func (s *IPSet) IsEmpty() bool {
+	return s == nil || (len(s.v4) == 0 && len(s.v6) == 0)
+}
+
+// Union returns a set of addresses that are in either set.
+func (s *IPSet) Union(o *IPSet) *IPSet {
+	return &IPSet{
+		v4: s.spans(false).union(o.spans(false)),
+		v6: s.spans(true).union(o.spans(true)),
+	}
+}
+
+// Intersect returns a set of addresses that are in both sets.
+func (s *IPSet) Intersect(o *IPSet) *IPSet {
+	return &IPSet{
+		v4: s.spans(false).intersect(o.spans(false)),
+		v6: s.spans(true).intersect(o.spans(true)),
+	}
+}
+
+// Difference returns a set of addresses that are in this set but not in the other one.
+func (s *IPSet) Difference(o *IPSet) *IPSet {
+	return &IPSet{
+		v4: s.spans(false).subtract(o.spans(false)),
+		v6: s.spans(true).subtract(o.spans(true)),
+	}
+}
+
+// SymmetricDifference returns a set of addresses that are in exactly one of the sets.
+func (s *IPSet) SymmetricDifference(o *IPSet) *IPSet {
+	return s.Union(o).Difference(s.Intersect(o))
+}
+
+// Complement returns a set of addresses of the universe that are not in this set.
+// Use a set containing `0.0.0.0/0` and `::/0` to get complement within the entire address space.
+func (s *IPSet) Complement(universe *IPSet) *IPSet {
+	return universe.Difference(s)
+}
+
+// Prefixes returns the minimal sorted list of networks covering the set.
+// IPv4 networks go first.
+func (s *IPSet) Prefixes() []*net.IPNet {
+	return append(
+		s.spans(false).networks(false),
+		s.spans(true).networks(true)...)
+}
+
+// Ranges returns the sorted list of disjoint IP ranges covering the set.
+// IPv4 ranges go first.
+func (s *IPSet) Ranges() []Range {
+	return append(
+		s.spans(false).ranges(false),
+		s.spans(true).ranges(true)...)
+}
+
+// spans returns IPv4 or IPv6 spans of the set.
+// The nil set is considered as empty.
+func (s *IPSet) spans(v6 bool) ipSpans {
+	switch {
+	case s == nil:
+		return nil
+	case v6:
+		return s.v6
+	default:
+		return s.v4
+	}
+}
+
+// add adds the span to the set.
+func (s *IPSet) add(span ipSpan, v6 bool) {
+	if span.empty() {
+		return // nothing to add
+	}
+	if v6 {
+		s.v6 = s.v6.union(ipSpans{span})
+	} else {
+		s.v4 = s.v4.union(ipSpans{span})
+	}
+}
+
+// remove removes the span from the set.
+func (s *IPSet) remove(span ipSpan, v6 bool) {
+	if span.empty() {
+		return // nothing to remove
+	}
+	if v6 {
+		s.v6 = s.v6.subtract(ipSpans{span})
+	} else {
+		s.v4 = s.v4.subtract(ipSpans{span})
+	}
+}
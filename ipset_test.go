@@ -0,0 +1,153 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleIPSet is an example of IPSet operations
+func ExampleIPSet() {
+	a, _ := ipx.NewIPSet(cidr("10.0.0.0/24"), cidr("10.0.1.0/24"))
+	b, _ := ipx.NewIPSet(cidr("10.0.0.128/25"), cidr("10.0.2.0/24"))
+
+	fmt.Println(a.Union(b).Prefixes())
+	fmt.Println(a.Intersect(b).Prefixes())
+	fmt.Println(a.Difference(b).Prefixes())
+	fmt.Println(a.SymmetricDifference(b).Prefixes())
+	// Output:
+	// [10.0.0.0/23 10.0.2.0/24]
+	// [10.0.0.128/25]
+	// [10.0.0.0/25 10.0.1.0/24]
+	// [10.0.0.0/25 10.0.1.0/24 10.0.2.0/24]
+}
+
+// newIPSet is a helper function to build IP set from CIDRs.
+func newIPSet(t require.TestingT, cidrs ...string) *ipx.IPSet {
+	var nwks []*net.IPNet
+	for _, s := range cidrs {
+		nwks = append(nwks, cidr(s))
+	}
+	set, err := ipx.NewIPSet(nwks...)
+	require.NoError(t, err, "failed to build IP set")
+	return set
+}
+
+// rangeStrings returns IP ranges as strings.
+func rangeStrings(rr []ipx.Range) []string {
+	var out []string
+	for _, r := range rr {
+		out = append(out, r.First.String()+"-"+r.Last.String())
+	}
+	return out
+}
+
+// TestIPSet unit tests for IPSet
+func TestIPSet(tt *testing.T) {
+	tt.Run("bad", func(t *testing.T) {
+		_, err := ipx.NewIPSet(nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+
+		var s ipx.IPSet
+		assert.ErrorIs(t, s.AddNet(nil), ipx.ErrInvalidNetwork)
+		assert.ErrorIs(t, s.RemoveNet(nil), ipx.ErrInvalidNetwork)
+		assert.ErrorIs(t, s.AddRange(ipx.NewRange(net.ParseIP("10.0.0.1"), net.ParseIP("::1"))), ipx.ErrVersionMismatch)
+		assert.ErrorIs(t, s.RemoveRange(ipx.NewRange(nil, net.ParseIP("::1"))), ipx.ErrInvalidIP)
+		assert.True(t, s.IsEmpty())
+		assert.False(t, s.Contains(net.ParseIP("bad")))
+		assert.Empty(t, s.Prefixes())
+	})
+
+	tt.Run("add_remove", func(t *testing.T) {
+		var s ipx.IPSet
+		require.NoError(t, s.AddNet(cidr("10.0.0.0/25")))
+		require.NoError(t, s.AddNet(cidr("10.0.0.128/25")))
+		require.NoError(t, s.AddRange(ipx.NewRange(net.ParseIP("10.0.1.0"), net.ParseIP("10.0.1.4"))))
+		require.NoError(t, s.AddNet(cidr("2001:db8::/64")))
+		assert.Equal(t, []string{"10.0.0.0/24", "10.0.1.0/30", "10.0.1.4/32", "2001:db8::/64"},
+			Networks(s.Prefixes()).Strings())
+		assert.Equal(t, []string{"10.0.0.0-10.0.1.4", "2001:db8::-2001:db8::ffff:ffff:ffff:ffff"},
+			rangeStrings(s.Ranges()))
+
+		require.NoError(t, s.RemoveNet(cidr("10.0.0.0/26")))
+		require.NoError(t, s.RemoveRange(ipx.NewRange(net.ParseIP("10.0.0.200"), net.ParseIP("10.0.1.0"))))
+		require.NoError(t, s.RemoveNet(cidr("2001:db8::/65")))
+		assert.Equal(t, []string{"10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/29", "10.0.1.1/32", "10.0.1.2/31", "10.0.1.4/32", "2001:db8:0:0:8000::/65"},
+			Networks(s.Prefixes()).Strings())
+
+		assert.True(t, s.Contains(net.ParseIP("10.0.0.199")))
+		assert.False(t, s.Contains(net.ParseIP("10.0.0.200")))
+		assert.False(t, s.Contains(net.ParseIP("2001:db8::1")))
+		assert.True(t, s.Contains(net.ParseIP("2001:db8:0:0:8000::1")))
+
+		// empty range is ignored
+		require.NoError(t, s.AddRange(ipx.NewRange(net.ParseIP("192.0.2.10"), net.ParseIP("192.0.2.1"))))
+		assert.False(t, s.Contains(net.ParseIP("192.0.2.5")))
+	})
+
+	tt.Run("extremes", func(t *testing.T) {
+		s := newIPSet(t, "0.0.0.0/1", "128.0.0.0/1", "::/1", "8000::/1")
+		assert.Equal(t, []string{"0.0.0.0/0", "::/0"}, Networks(s.Prefixes()).Strings())
+
+		s = s.Difference(newIPSet(t, "255.255.255.255/32", "::/128"))
+		assert.Equal(t, []string{"0.0.0.0-255.255.255.254", "::1-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			rangeStrings(s.Ranges()))
+	})
+
+	tt.Run("operations", func(t *testing.T) {
+		a := newIPSet(t, "10.0.0.0/16", "192.168.0.0/24", "2001:db8::/32")
+		b := newIPSet(t, "10.0.128.0/17", "10.1.0.0/16", "2001:db8:1::/48")
+
+		assert.Equal(t, []string{"10.0.0.0/15", "192.168.0.0/24", "2001:db8::/32"},
+			Networks(a.Union(b).Prefixes()).Strings())
+		assert.Equal(t, []string{"10.0.128.0/17", "2001:db8:1::/48"},
+			Networks(a.Intersect(b).Prefixes()).Strings())
+		assert.Equal(t, []string{"10.0.0.0/17", "192.168.0.0/24", "2001:db8::/48", "2001:db8:2::/47", "2001:db8:4::/46",
+			"2001:db8:8::/45", "2001:db8:10::/44", "2001:db8:20::/43", "2001:db8:40::/42", "2001:db8:80::/41",
+			"2001:db8:100::/40", "2001:db8:200::/39", "2001:db8:400::/38", "2001:db8:800::/37", "2001:db8:1000::/36",
+			"2001:db8:2000::/35", "2001:db8:4000::/34", "2001:db8:8000::/33"},
+			Networks(a.Difference(b).Prefixes()).Strings())
+		assert.Equal(t, []string{"10.0.0.0/17", "10.1.0.0/16", "192.168.0.0/24"},
+			Networks(a.SymmetricDifference(b).Prefixes()).Strings()[:3])
+
+		// nil set is empty
+		assert.Equal(t, a.Prefixes(), a.Union(nil).Prefixes())
+		assert.True(t, a.Intersect(nil).IsEmpty())
+	})
+
+	tt.Run("complement", func(t *testing.T) {
+		s := newIPSet(t, "10.0.0.0/8", "::/1")
+		all := newIPSet(t, "0.0.0.0/0", "::/0")
+		assert.Equal(t, []string{"0.0.0.0/5", "8.0.0.0/7", "11.0.0.0/8", "12.0.0.0/6", "16.0.0.0/4",
+			"32.0.0.0/3", "64.0.0.0/2", "128.0.0.0/1", "8000::/1"},
+			Networks(s.Complement(all).Prefixes()).Strings())
+
+		private := newIPSet(t, "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16")
+		assert.Equal(t, []string{"172.16.0.0/12", "192.168.0.0/16"},
+			Networks(s.Complement(private).Prefixes()).Strings())
+	})
+}
+
+// BenchmarkIPSetDifference performance benchmarks for IPSet.Difference
+func BenchmarkIPSetDifference(bb *testing.B) {
+	var holes []*net.IPNet
+	for i := 0; i < 4096; i++ {
+		holes = append(holes, &net.IPNet{
+			IP:   net.IPv4(10, byte(i>>4), byte(i<<4), 0).To4(),
+			Mask: net.CIDRMask(28, 32),
+		})
+	}
+	a := newIPSet(bb, "10.0.0.0/8")
+	b, err := ipx.NewIPSet(holes...)
+	require.NoError(bb, err)
+
+	bb.ReportAllocs()
+	bb.ResetTimer()
+	for i := 0; i < bb.N; i++ {
+		_ = a.Difference(b)
+	}
+}
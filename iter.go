@@ -24,6 +24,8 @@ type v6IPIter struct {
 const (
 	ipIterFlagV6 = 1 << iota
 	ipIterFlagNegative
+	ipIterFlagInclusive // the limit is included, positive step only
+	ipIterFlagDone      // the iteration is over
 )
 
 // IPIter permits iteration over a series of ips. It is always start inclusive.
@@ -43,6 +45,9 @@ func (i *IPIter) IP() net.IP {
 
 // Next returns true when the underlying pointer has been successfully updated with the next value.
 func (i *IPIter) Next() bool {
+	if i.flags&ipIterFlagDone > 0 {
+		return false
+	}
 	if i.flags&ipIterFlagV6 > 0 {
 		if i.flags&ipIterFlagNegative > 0 {
 			if i.v6.val.Cmp(i.v6.limit) != 1 {
@@ -55,10 +60,15 @@ func (i *IPIter) Next() bool {
 			}
 			return true
 		}
-		if i.v6.val.Cmp(i.v6.limit) != -1 {
+		if cmp := i.v6.val.Cmp(i.v6.limit); cmp == 1 || (cmp == 0 && i.flags&ipIterFlagInclusive == 0) {
 			return false
 		}
 		store128(i.v6.val, i.ip)
+		if i.flags&ipIterFlagInclusive > 0 && i.v6.limit.Sub(i.v6.val).Cmp(i.v6.incr) == -1 {
+			// the next value is beyond the limit, stop on the next call
+			i.flags |= ipIterFlagDone
+			return true
+		}
 		i.v6.val = i.v6.val.Add(i.v6.incr)
 		return true
 	}
@@ -70,10 +80,15 @@ func (i *IPIter) Next() bool {
 		i.v4.val -= i.v4.incr
 		return true
 	}
-	if i.v4.val >= i.v4.limit {
+	if i.v4.val > i.v4.limit || (i.v4.val == i.v4.limit && i.flags&ipIterFlagInclusive == 0) {
 		return false
 	}
 	store32(i.v4.val, i.ip)
+	if i.flags&ipIterFlagInclusive > 0 && i.v4.limit-i.v4.val < i.v4.incr {
+		// the next value is beyond the limit, stop on the next call
+		i.flags |= ipIterFlagDone
+		return true
+	}
 	i.v4.val += i.v4.incr
 	return true
 }
@@ -132,15 +147,44 @@ func (n *NetIter) Next() bool {
 	return n.ips.Next()
 }
 
+// Seek moves the iterator, so the next call to `Next` returns the network
+// containing the given IP if it is still within the iterator limit.
+func (n *NetIter) Seek(addr net.IP) error {
+	if n.net == nil {
+		return nil // empty iterator, nothing to seek
+	}
+
+	u, v6, err := loadIP(addr)
+	if err != nil {
+		return err
+	}
+
+	ones, bits := n.net.Mask.Size()
+	if v6 {
+		return n.ips.Seek(storeIP(u.And(ip6Net{prefix: uint8(ones)}.mask()), true))
+	}
+	if bits != 8*net.IPv4len {
+		return ErrVersionMismatch
+	}
+	return n.ips.Seek(storeIP(Uint128{Lo: uint64(uint32(u.Lo) & ip4Net{prefix: uint8(ones)}.mask())}, false))
+}
+
+// Skip moves the iterator n networks ahead without iteration.
+// If the iterator limit is reached, the iteration is over.
+func (n *NetIter) Skip(count Uint128) {
+	n.ips.Skip(count)
+}
+
 // IterNet returns an iterator for the given increment starting with the provided network
+// Host bits of the networks are ignored.
 func IterNet(start *net.IPNet, step int, end *net.IPNet) *NetIter {
-	if step == 0 {
+	if start = canonical(start); start == nil || step == 0 {
 		return new(NetIter)
 	}
 
 	var endIP net.IP
 	if end != nil {
-		if !bytes.Equal(start.Mask, end.Mask) {
+		if end = canonical(end); end == nil || !bytes.Equal(start.Mask, end.Mask) {
 			return new(NetIter)
 		}
 		endIP = end.IP
@@ -213,3 +257,69 @@ func resolveIPs6(start net.IP, step int, end net.IP, shift uint) *IPIter {
 	}
 	return iterIPv6(sIP, Uint128{Lo: uint64(step * -1)}.Lsh(shift), eIP)
 }
+
+// Seek moves the iterator, so the next call to `Next` returns the given IP
+// if it is still within the iterator limit. The IP version should match.
+// Note, no step alignment is applied.
+func (i *IPIter) Seek(addr net.IP) error {
+	if i.ip == nil {
+		return nil // empty iterator, nothing to seek
+	}
+
+	u, v6, err := loadIP(addr)
+	if err != nil {
+		return err
+	}
+	if v6 != (i.flags&ipIterFlagV6 > 0) {
+		return ErrVersionMismatch
+	}
+
+	if v6 {
+		i.v6.val = u
+	} else {
+		i.v4.val = uint32(u.Lo)
+	}
+	i.flags &^= ipIterFlagDone
+	return nil
+}
+
+// Skip moves the iterator n steps ahead without iteration.
+// If the iterator limit is reached, the iteration is over.
+func (i *IPIter) Skip(n Uint128) {
+	if n.IsZero() || i.ip == nil || i.flags&ipIterFlagDone > 0 {
+		return // nothing to skip
+	}
+
+	var val, incr, limit Uint128
+	if i.flags&ipIterFlagV6 > 0 {
+		val, incr, limit = i.v6.val, i.v6.incr, i.v6.limit
+	} else {
+		val = Uint128{Lo: uint64(i.v4.val)}
+		incr = Uint128{Lo: uint64(i.v4.incr)}
+		limit = Uint128{Lo: uint64(i.v4.limit)}
+	}
+
+	hi, d := u128.Mul(n, incr)
+	ok := hi.IsZero()
+
+	var next Uint128
+	if i.flags&ipIterFlagNegative > 0 {
+		next = val.Sub(d)
+		ok = ok && d.Cmp(val) <= 0 && next.Cmp(limit) > 0
+	} else {
+		next = val.Add(d)
+		cmp := next.Cmp(limit)
+		ok = ok && next.Cmp(val) >= 0 &&
+			(cmp < 0 || (cmp == 0 && i.flags&ipIterFlagInclusive > 0))
+	}
+	if !ok {
+		i.flags |= ipIterFlagDone
+		return
+	}
+
+	if i.flags&ipIterFlagV6 > 0 {
+		i.v6.val = next
+	} else {
+		i.v4.val = uint32(next.Lo)
+	}
+}
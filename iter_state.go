@@ -0,0 +1,271 @@
+package ipx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// iterStateVersion is the current version of the serialized iterator state.
+const iterStateVersion = 1
+
+const (
+	iterStateKindIP  = 1
+	iterStateKindNet = 2
+)
+
+// iterStateFlags is a set of all known iterator flags.
+const iterStateFlags = ipIterFlagV6 | ipIterFlagNegative | ipIterFlagInclusive | ipIterFlagDone
+
+// IterState is an opaque checkpoint of IPIter or NetIter position.
+// It can be serialized in binary or JSON format and later
+// used to resume the iteration from the same position.
+type IterState struct {
+	kind  uint8
+	flags uint8
+	bits  uint8 // address length: 32, 128 or 0 for an empty iterator
+	ones  uint8 // network prefix length, NetIter only
+	val   Uint128
+	incr  Uint128
+	limit Uint128
+}
+
+// iterStateJSON is the JSON representation of IterState.
+type iterStateJSON struct {
+	Version int     `json:"version"`
+	Kind    string  `json:"kind"`
+	Flags   uint8   `json:"flags"`
+	Bits    uint8   `json:"bits"`
+	Ones    uint8   `json:"ones,omitempty"`
+	Val     Uint128 `json:"val"`
+	Incr    Uint128 `json:"incr"`
+	Limit   Uint128 `json:"limit"`
+}
+
+// State returns the current position of the iterator.
+func (i *IPIter) State() IterState {
+	s := IterState{kind: iterStateKindIP}
+	s.setIter(i)
+	return s
+}
+
+// State returns the current position of the iterator.
+func (n *NetIter) State() IterState {
+	s := IterState{kind: iterStateKindNet}
+	s.setIter(&n.ips)
+	if n.net != nil && s.bits != 0 {
+		ones, _ := n.net.Mask.Size()
+		s.ones = uint8(ones)
+	}
+	return s
+}
+
+// ResumeIPIter returns an iterator continuing from the saved state.
+func ResumeIPIter(s IterState) (*IPIter, error) {
+	if s.kind != iterStateKindIP {
+		return nil, ErrInvalidIterState
+	}
+	return s.iter(), nil
+}
+
+// ResumeNetIter returns an iterator continuing from the saved state.
+func ResumeNetIter(s IterState) (*NetIter, error) {
+	if s.kind != iterStateKindNet {
+		return nil, ErrInvalidIterState
+	}
+	if s.bits == 0 {
+		return new(NetIter), nil
+	}
+
+	return &NetIter{
+		ips: *s.iter(),
+		net: &net.IPNet{Mask: net.CIDRMask(int(s.ones), int(s.bits))},
+	}, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s IterState) MarshalBinary() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	size := int(s.bits) / 8
+	buf := make([]byte, 4, 5+3*size)
+	buf[0], buf[1], buf[2], buf[3] = iterStateVersion, s.kind, s.flags, s.bits
+	for _, u := range []Uint128{s.val, s.incr, s.limit} {
+		if size == net.IPv4len {
+			buf = append(buf, make([]byte, size)...)
+			store32(uint32(u.Lo), buf[len(buf)-size:])
+		} else if size == net.IPv6len {
+			buf = append(buf, make([]byte, size)...)
+			store128(u, buf[len(buf)-size:])
+		}
+	}
+	if s.kind == iterStateKindNet && s.bits != 0 {
+		buf = append(buf, s.ones)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *IterState) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 || data[0] != iterStateVersion {
+		return ErrInvalidIterState
+	}
+
+	out := IterState{kind: data[1], flags: data[2], bits: data[3]}
+	size := int(out.bits) / 8
+	expected := 4 + 3*size
+	if out.kind == iterStateKindNet && out.bits != 0 {
+		expected++
+	}
+	if len(data) != expected {
+		return ErrInvalidIterState
+	}
+
+	vals := []*Uint128{&out.val, &out.incr, &out.limit}
+	for k, p := 0, 4; size > 0 && k < len(vals); k, p = k+1, p+size {
+		if size == net.IPv4len {
+			*vals[k] = Uint128{Lo: uint64(load32(data[p : p+size]))}
+		} else {
+			*vals[k] = load128(data[p : p+size])
+		}
+	}
+	if out.kind == iterStateKindNet && out.bits != 0 {
+		out.ones = data[len(data)-1]
+	}
+
+	if err := out.validate(); err != nil {
+		return err
+	}
+	*s = out
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s IterState) MarshalJSON() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	kind := "ip"
+	if s.kind == iterStateKindNet {
+		kind = "net"
+	}
+
+	return json.Marshal(iterStateJSON{
+		Version: iterStateVersion,
+		Kind:    kind,
+		Flags:   s.flags,
+		Bits:    s.bits,
+		Ones:    s.ones,
+		Val:     s.val,
+		Incr:    s.incr,
+		Limit:   s.limit,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *IterState) UnmarshalJSON(data []byte) error {
+	var v iterStateJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidIterState, err)
+	}
+	if v.Version != iterStateVersion {
+		return ErrInvalidIterState
+	}
+
+	out := IterState{
+		flags: v.Flags,
+		bits:  v.Bits,
+		ones:  v.Ones,
+		val:   v.Val,
+		incr:  v.Incr,
+		limit: v.Limit,
+	}
+	switch v.Kind {
+	case "ip":
+		out.kind = iterStateKindIP
+	case "net":
+		out.kind = iterStateKindNet
+	default:
+		return ErrInvalidIterState
+	}
+
+	if err := out.validate(); err != nil {
+		return err
+	}
+	*s = out
+	return nil
+}
+
+// setIter saves position of the IP iterator.
+func (s *IterState) setIter(i *IPIter) {
+	switch {
+	case i.ip == nil:
+		// empty iterator, nothing to save
+	case i.flags&ipIterFlagV6 > 0:
+		s.bits = 8 * net.IPv6len
+		s.flags = i.flags
+		s.val, s.incr, s.limit = i.v6.val, i.v6.incr, i.v6.limit
+	default:
+		s.bits = 8 * net.IPv4len
+		s.flags = i.flags
+		s.val = Uint128{Lo: uint64(i.v4.val)}
+		s.incr = Uint128{Lo: uint64(i.v4.incr)}
+		s.limit = Uint128{Lo: uint64(i.v4.limit)}
+	}
+}
+
+// iter returns the IP iterator of the saved position.
+func (s *IterState) iter() *IPIter {
+	var i *IPIter
+	switch s.bits {
+	case 8 * net.IPv6len:
+		i = iterIPv6(s.val, s.incr, s.limit)
+	case 8 * net.IPv4len:
+		i = iterIPv4(uint32(s.val.Lo), uint32(s.incr.Lo), uint32(s.limit.Lo))
+	default:
+		return new(IPIter)
+	}
+
+	i.flags = s.flags
+	return i
+}
+
+// validate checks the state is consistent.
+func (s *IterState) validate() error {
+	if s.kind != iterStateKindIP && s.kind != iterStateKindNet {
+		return ErrInvalidIterState
+	}
+	if s.flags&^iterStateFlags != 0 {
+		return ErrInvalidIterState
+	}
+
+	switch s.bits {
+	case 0:
+		if s.flags != 0 || s.ones != 0 || !s.val.IsZero() || !s.incr.IsZero() || !s.limit.IsZero() {
+			return ErrInvalidIterState
+		}
+	case 8 * net.IPv4len:
+		if s.flags&ipIterFlagV6 > 0 || s.val.Cmp64(maxUint32) > 0 ||
+			s.incr.Cmp64(maxUint32) > 0 || s.limit.Cmp64(maxUint32) > 0 {
+			return ErrInvalidIterState
+		}
+	case 8 * net.IPv6len:
+		if s.flags&ipIterFlagV6 == 0 {
+			return ErrInvalidIterState
+		}
+	default:
+		return ErrInvalidIterState
+	}
+
+	if s.ones > s.bits || (s.kind == iterStateKindIP && s.ones != 0) {
+		return ErrInvalidIterState
+	}
+	if s.bits != 0 && s.incr.IsZero() {
+		return ErrInvalidIterState // would never move
+	}
+	return nil
+}
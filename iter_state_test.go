@@ -0,0 +1,234 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleResumeIPIter is an example of iterator checkpoint
+func ExampleResumeIPIter() {
+	iter := ipx.IterIP(net.ParseIP("2001:db8::"), 1, nil)
+	for i := 0; i < 2 && iter.Next(); i++ {
+		fmt.Println(iter.IP())
+	}
+
+	data, _ := json.Marshal(iter.State())
+
+	var state ipx.IterState
+	_ = json.Unmarshal(data, &state)
+	iter, _ = ipx.ResumeIPIter(state)
+	for i := 0; i < 2 && iter.Next(); i++ {
+		fmt.Println(iter.IP())
+	}
+	// Output:
+	// 2001:db8::
+	// 2001:db8::1
+	// 2001:db8::2
+	// 2001:db8::3
+}
+
+// ipStrings collects all the rest IPs of the iterator.
+func ipStrings(iter *ipx.IPIter) []string {
+	var out []string
+	for iter.Next() {
+		out = append(out, iter.IP().String())
+	}
+	return out
+}
+
+// TestIterState unit tests for iterator state
+func TestIterState(tt *testing.T) {
+	tt.Run("ip", func(t *testing.T) {
+		for _, c := range []struct {
+			start string
+			step  int
+			end   string
+		}{
+			{"10.0.0.0", 2, "10.0.0.9"},
+			{"10.0.0.9", -2, "10.0.0.0"},
+			{"2001:db8::", 3, "2001:db8::10"},
+			{"2001:db8::10", -3, "2001:db8::"},
+		} {
+			iter := ipx.IterIP(net.ParseIP(c.start), c.step, net.ParseIP(c.end))
+			require.True(t, iter.Next())
+			expected := ipStrings(ipx.IterIP(net.ParseIP(c.start), c.step, net.ParseIP(c.end)))[1:]
+
+			bin, err := iter.State().MarshalBinary()
+			require.NoError(t, err)
+			var s1 ipx.IterState
+			require.NoError(t, s1.UnmarshalBinary(bin))
+			i1, err := ipx.ResumeIPIter(s1)
+			require.NoError(t, err)
+			assert.Equal(t, expected, ipStrings(i1), c.start)
+
+			js, err := json.Marshal(iter.State())
+			require.NoError(t, err)
+			var s2 ipx.IterState
+			require.NoError(t, json.Unmarshal(js, &s2))
+			i2, err := ipx.ResumeIPIter(s2)
+			require.NoError(t, err)
+			assert.Equal(t, expected, ipStrings(i2), c.start)
+
+			// the original iterator is not affected
+			assert.Equal(t, expected, ipStrings(iter), c.start)
+		}
+	})
+
+	tt.Run("done", func(t *testing.T) {
+		r := ipx.NewRange(net.ParseIP("255.255.255.254"), net.ParseIP("255.255.255.255"))
+		iter := r.Addresses()
+		require.True(t, iter.Next())
+		require.True(t, iter.Next())
+
+		i, err := ipx.ResumeIPIter(iter.State())
+		require.NoError(t, err)
+		assert.False(t, i.Next())
+	})
+
+	tt.Run("net", func(t *testing.T) {
+		iter := ipx.IterNet(cidr("2001:db8::/64"), 1, nil)
+		require.True(t, iter.Next())
+
+		js, err := json.Marshal(iter.State())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"version":1,"kind":"net","flags":1,"bits":128,"ones":64,`+
+			`"val":"42540766411282592875350729025363378176","incr":"18446744073709551616","limit":"340282366920938463463374607431768211455"}`,
+			string(js))
+
+		var s ipx.IterState
+		require.NoError(t, json.Unmarshal(js, &s))
+		n, err := ipx.ResumeNetIter(s)
+		require.NoError(t, err)
+		require.True(t, n.Next())
+		assert.Equal(t, "2001:db8:0:1::/64", n.Net().String())
+
+		_, err = ipx.ResumeIPIter(s)
+		assert.ErrorIs(t, err, ipx.ErrInvalidIterState)
+	})
+
+	tt.Run("empty", func(t *testing.T) {
+		bin, err := ipx.IterIP(net.ParseIP("10.0.0.1"), 0, nil).State().MarshalBinary()
+		require.NoError(t, err)
+		var s ipx.IterState
+		require.NoError(t, s.UnmarshalBinary(bin))
+		i, err := ipx.ResumeIPIter(s)
+		require.NoError(t, err)
+		assert.False(t, i.Next())
+
+		bin, err = ipx.IterNet(cidr("10.0.0.0/24"), 0, nil).State().MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, s.UnmarshalBinary(bin))
+		n, err := ipx.ResumeNetIter(s)
+		require.NoError(t, err)
+		assert.False(t, n.Next())
+	})
+
+	tt.Run("bad", func(t *testing.T) {
+		var s ipx.IterState
+		_, err := s.MarshalBinary()
+		assert.ErrorIs(t, err, ipx.ErrInvalidIterState)
+		_, err = ipx.ResumeIPIter(s)
+		assert.ErrorIs(t, err, ipx.ErrInvalidIterState)
+
+		for _, data := range [][]byte{
+			nil,
+			{2, 1, 0, 0},    // unknown version
+			{1, 3, 0, 0},    // unknown kind
+			{1, 1, 0, 32},   // truncated
+			{1, 1, 0x10, 0}, // unknown flag
+			{1, 1, 1, 32, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 2}, // version mismatch
+		} {
+			assert.ErrorIs(t, s.UnmarshalBinary(data), ipx.ErrInvalidIterState, data)
+		}
+
+		for _, data := range []string{
+			`[]`,
+			`{"version":2,"kind":"ip"}`,
+			`{"version":1,"kind":"foo"}`,
+			`{"version":1,"kind":"ip","bits":32,"val":"4294967296","incr":"1","limit":"2"}`,
+			`{"version":1,"kind":"net","bits":32,"ones":33,"val":"1","incr":"1","limit":"2"}`,
+			`{"version":1,"kind":"ip","bits":32,"val":"1","incr":"0","limit":"10"}`,
+			`{"version":1,"kind":"ip","bits":128,"val":"1","incr":"0","limit":"10"}`,
+		} {
+			assert.ErrorIs(t, json.Unmarshal([]byte(data), &s), ipx.ErrInvalidIterState, data)
+		}
+	})
+}
+
+// TestIterSeekSkip unit tests for iterator Seek and Skip
+func TestIterSeekSkip(tt *testing.T) {
+	tt.Run("seek", func(t *testing.T) {
+		iter := ipx.IterIP(net.ParseIP("10.0.0.0"), 1, net.ParseIP("10.0.0.10"))
+		require.NoError(t, iter.Seek(net.ParseIP("10.0.0.7")))
+		assert.Equal(t, []string{"10.0.0.7", "10.0.0.8", "10.0.0.9"}, ipStrings(iter))
+
+		// seek back after the end
+		require.NoError(t, iter.Seek(net.ParseIP("10.0.0.9")))
+		assert.Equal(t, []string{"10.0.0.9"}, ipStrings(iter))
+
+		assert.ErrorIs(t, iter.Seek(net.ParseIP("::1")), ipx.ErrVersionMismatch)
+		assert.ErrorIs(t, iter.Seek(nil), ipx.ErrInvalidIP)
+
+		r := ipx.NewRange(net.ParseIP("::"), net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"))
+		iter = r.Addresses()
+		require.NoError(t, iter.Seek(net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe")))
+		assert.Equal(t, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			ipStrings(iter))
+	})
+
+	tt.Run("seek_net", func(t *testing.T) {
+		n := ipx.IterNet(cidr("10.0.0.0/24"), 1, cidr("10.0.8.0/24"))
+		require.NoError(t, n.Seek(net.ParseIP("10.0.6.77")))
+		var got []string
+		for n.Next() {
+			got = append(got, n.Net().String())
+		}
+		assert.Equal(t, []string{"10.0.6.0/24", "10.0.7.0/24"}, got)
+
+		assert.ErrorIs(t, n.Seek(net.ParseIP("2001:db8::")), ipx.ErrVersionMismatch)
+		assert.NoError(t, new(ipx.NetIter).Seek(net.ParseIP("10.0.0.1")))
+	})
+
+	tt.Run("skip", func(t *testing.T) {
+		iter := ipx.IterIP(net.ParseIP("10.0.0.0"), 2, net.ParseIP("10.0.0.20"))
+		iter.Skip(ipx.Uint128{Lo: 3})
+		assert.Equal(t, []string{"10.0.0.6", "10.0.0.8"}, ipStrings(iter)[:2])
+
+		iter = ipx.IterIP(net.ParseIP("10.0.0.0"), 2, net.ParseIP("10.0.0.20"))
+		iter.Skip(ipx.Uint128{Lo: 10})
+		assert.Empty(t, ipStrings(iter))
+
+		iter = ipx.IterIP(net.ParseIP("10.0.0.20"), -2, net.ParseIP("10.0.0.0"))
+		iter.Skip(ipx.Uint128{Lo: 9})
+		assert.Equal(t, []string{"10.0.0.2"}, ipStrings(iter))
+
+		iter = ipx.IterIP(net.ParseIP("10.0.0.20"), -2, net.ParseIP("10.0.0.0"))
+		iter.Skip(ipx.Uint128{Lo: 100})
+		assert.Empty(t, ipStrings(iter))
+
+		// inclusive limit and overflow
+		r := ipx.NewRange(net.ParseIP("::"), net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"))
+		iter = r.Addresses()
+		iter.Skip(ipx.Uint128{Hi: maxUint64, Lo: maxUint64})
+		assert.Equal(t, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}, ipStrings(iter))
+
+		iter = ipx.IterIP(net.ParseIP("2001:db8::"), 1<<20, nil)
+		iter.Skip(ipx.Uint128{Hi: 1 << 62})
+		assert.Empty(t, ipStrings(iter))
+	})
+
+	tt.Run("skip_net", func(t *testing.T) {
+		n := ipx.IterNet(cidr("2001:db8::/64"), 1, nil)
+		n.Skip(ipx.Uint128{Lo: 0x10000})
+		require.True(t, n.Next())
+		assert.Equal(t, "2001:db8:1::/64", n.Net().String())
+	})
+}
+
+const maxUint64 = ^uint64(0)
@@ -78,7 +78,7 @@ func ExampleIterIP() {
 	// 10.0.0.0
 }
 
-func ExampleIterIP_IP6() {
+func ExampleIterIP_ip6() {
 	ip := net.ParseIP("2001:db8::")
 	for i, iter := 0, ipx.IterIP(ip, 1e18, nil); i < 5 && iter.Next(); i++ {
 		ip = iter.IP()
@@ -162,7 +162,7 @@ func ExampleIterNet() {
 	// 10.0.0.0/16
 }
 
-func ExampleIterNet_IP6() {
+func ExampleIterNet_ip6() {
 	ipN := cidr("2001:db8::/64")
 	for i, iter := 0, ipx.IterNet(ipN, 1e18, nil); i < 5 && iter.Next(); i++ {
 		ipN = iter.Net()
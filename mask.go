@@ -0,0 +1,150 @@
+package ipx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
+)
+
+// ParseNetmask parses the network mask in one of the following notations:
+//   - `255.255.255.0` or `ffff:ffff::` dotted (address) form,
+//   - `/24` or `24` prefix length form,
+//   - `ffffff00` or `0xffffff00` hex form, as returned by net.IPMask.String().
+//
+// The v6 flag selects the mask length: 4 bytes for IPv4 and 16 bytes for IPv6,
+// ErrVersionMismatch is returned if the mask has another length.
+// ErrInvalidMask is returned if the mask is not contiguous.
+func ParseNetmask(s string, v6 bool) (net.IPMask, error) {
+	return parseMask(s, v6, false)
+}
+
+// ParseWildcardMask parses the wildcard (inverse) mask, as used by Cisco ACLs,
+// and returns the corresponding network mask: `0.0.0.255` is `255.255.255.0`.
+// The same notations as for ParseNetmask() are supported
+// except the prefix length form.
+func ParseWildcardMask(s string, v6 bool) (net.IPMask, error) {
+	return parseMask(s, v6, true)
+}
+
+// ValidateMask returns ErrInvalidMask if the mask is neither 4 nor 16 bytes long
+// or is not contiguous.
+func ValidateMask(mask net.IPMask) error {
+	if _, bits := mask.Size(); bits != 8*net.IPv4len && bits != 8*net.IPv6len {
+		return fmt.Errorf("%w: %s", ErrInvalidMask, mask)
+	}
+	return nil
+}
+
+// FormatNetmask returns the mask in dotted (address) form, like `255.255.255.0`.
+func FormatNetmask(mask net.IPMask) string {
+	return net.IP(mask).String()
+}
+
+// FormatWildcardMask returns the inverted mask in dotted (address) form, like `0.0.0.255`.
+func FormatWildcardMask(mask net.IPMask) string {
+	return net.IP(invertMask(mask)).String()
+}
+
+// FormatHexMask returns the mask in "0x" prefixed hex form, like `0xffffff00`.
+func FormatHexMask(mask net.IPMask) string {
+	return "0x" + hex.EncodeToString(mask)
+}
+
+// Hostmask returns the host mask of the network, like `0.0.0.255` for /24.
+// It is the same as the wildcard mask of the network.
+func Hostmask(network *net.IPNet) net.IP {
+	if network = canonical(network); network == nil {
+		return nil // no network, no mask
+	}
+
+	ones, bits := network.Mask.Size()
+	return net.IP(invertMask(net.CIDRMask(ones, bits)))
+}
+
+// PrefixForHosts returns the smallest prefix length of a network
+// having at least n usable hosts. Network and broadcast addresses are reserved,
+// except the point-to-point (2 hosts) and single host networks
+// which are /31 and /32 (/127 and /128 for IPv6, see RFC 3021 and RFC 6164).
+// ErrOverflow is returned if the address space is too small.
+func PrefixForHosts(n Uint128, v6 bool) (int, error) {
+	size, version := 8*net.IPv4len, 4
+	if v6 {
+		size, version = 8*net.IPv6len, 6
+	}
+
+	var hostBits int
+	switch {
+	case n.Cmp64(1) <= 0:
+		return size, nil // single host
+	case n.Equals64(2):
+		return size - 1, nil // point-to-point
+	case n.Equals(u128.Max()):
+		hostBits = 8*net.IPv6len + 1
+	default:
+		// network and broadcast addresses are reserved
+		hostBits = n.Add64(1).BitLen()
+	}
+
+	if hostBits > size {
+		return 0, fmt.Errorf("%w: %s hosts do not fit IPv%d network", ErrOverflow, n, version)
+	}
+	return size - hostBits, nil
+}
+
+// parseMask parses the network or wildcard mask.
+func parseMask(s string, v6, wildcard bool) (net.IPMask, error) {
+	s = strings.TrimSpace(s)
+	size := net.IPv4len
+	if v6 {
+		size = net.IPv6len
+	}
+
+	var mask net.IPMask
+	switch ip := net.ParseIP(s); {
+	case !wildcard && (strings.HasPrefix(s, "/") || len(s) <= 3):
+		// hex masks are longer, so it is prefix length
+		p := strings.TrimPrefix(s, "/")
+		ones, err := strconv.Atoi(p)
+		if err != nil || p[0] < '0' || p[0] > '9' || ones > 8*size {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMask, s)
+		}
+		return net.CIDRMask(ones, 8*size), nil
+
+	case ip != nil:
+		mask = net.IPMask(ip)
+		if !strings.Contains(s, ":") {
+			mask = net.IPMask(ip.To4())
+		}
+
+	default:
+		raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+		if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMask, s)
+		}
+		mask = raw
+	}
+
+	if len(mask) != size {
+		return nil, fmt.Errorf("%w: %q", ErrVersionMismatch, s)
+	}
+	if wildcard {
+		mask = invertMask(mask)
+	}
+	if err := ValidateMask(mask); err != nil {
+		return nil, fmt.Errorf("%w: %q is not contiguous", ErrInvalidMask, s)
+	}
+	return mask, nil
+}
+
+// invertMask returns the inverted copy of the mask.
+func invertMask(mask net.IPMask) net.IPMask {
+	out := make(net.IPMask, len(mask))
+	for i, b := range mask {
+		out[i] = ^b
+	}
+	return out
+}
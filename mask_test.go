@@ -0,0 +1,131 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleParseWildcardMask is an example of mask conversions
+func ExampleParseWildcardMask() {
+	mask, _ := ipx.ParseWildcardMask("0.0.0.255", false)
+	ones, _ := mask.Size()
+	fmt.Println(ipx.FormatNetmask(mask), ones, ipx.FormatHexMask(mask))
+	// Output:
+	// 255.255.255.0 24 0xffffff00
+}
+
+// TestParseNetmask unit tests for ParseNetmask and ParseWildcardMask
+func TestParseNetmask(tt *testing.T) {
+	tt.Run("netmask", func(t *testing.T) {
+		for _, c := range []struct {
+			s    string
+			v6   bool
+			ones int
+		}{
+			{"255.255.255.0", false, 24},
+			{" /24 ", false, 24},
+			{"24", false, 24},
+			{"0", false, 0},
+			{"ffffff00", false, 24},
+			{"0xFFFFFFFF", false, 32},
+			{"ffff:ffff::", true, 32},
+			{"/64", true, 64},
+			{"128", true, 128},
+			{"0xffffffffffffffff0000000000000000", true, 64},
+		} {
+			mask, err := ipx.ParseNetmask(c.s, c.v6)
+			if assert.NoError(t, err, c.s) {
+				ones, bits := mask.Size()
+				assert.Equal(t, c.ones, ones, c.s)
+				assert.Equal(t, map[bool]int{false: 32, true: 128}[c.v6], bits, c.s)
+			}
+		}
+	})
+
+	tt.Run("wildcard", func(t *testing.T) {
+		mask, err := ipx.ParseWildcardMask("0.0.0.255", false)
+		require.NoError(t, err)
+		assert.Equal(t, net.CIDRMask(24, 32), mask)
+		mask, err = ipx.ParseWildcardMask("0x0000ffff", false)
+		require.NoError(t, err)
+		assert.Equal(t, net.CIDRMask(16, 32), mask)
+		mask, err = ipx.ParseWildcardMask("::ffff:ffff:ffff:ffff", true)
+		require.NoError(t, err)
+		assert.Equal(t, net.CIDRMask(64, 128), mask)
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{"", "/33", "-1", "+1", "/x", "255.0.255.0", "0.0.0.255", "ff00ff00", "ffffff", "foo"} {
+			_, err := ipx.ParseNetmask(s, false)
+			assert.ErrorIs(t, err, ipx.ErrInvalidMask, s)
+		}
+		for _, s := range []string{"/24", "24", "255.255.255.0", "0.255.0.255"} {
+			_, err := ipx.ParseWildcardMask(s, false)
+			assert.ErrorIs(t, err, ipx.ErrInvalidMask, s)
+		}
+		_, err := ipx.ParseNetmask("255.255.255.0", true)
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+		_, err = ipx.ParseNetmask("ffff::", false)
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+	})
+}
+
+// TestFormatMask unit tests for mask formatting
+func TestFormatMask(t *testing.T) {
+	mask := net.CIDRMask(20, 32)
+	assert.Equal(t, "255.255.240.0", ipx.FormatNetmask(mask))
+	assert.Equal(t, "0.0.15.255", ipx.FormatWildcardMask(mask))
+	assert.Equal(t, "0xfffff000", ipx.FormatHexMask(mask))
+
+	mask = net.CIDRMask(48, 128)
+	assert.Equal(t, "ffff:ffff:ffff::", ipx.FormatNetmask(mask))
+	assert.Equal(t, "::ffff:ffff:ffff:ffff:ffff", ipx.FormatWildcardMask(mask))
+
+	assert.NoError(t, ipx.ValidateMask(mask))
+	assert.ErrorIs(t, ipx.ValidateMask(net.IPv4Mask(255, 0, 255, 0)), ipx.ErrInvalidMask)
+	assert.ErrorIs(t, ipx.ValidateMask(net.IPMask{255}), ipx.ErrInvalidMask)
+}
+
+// TestHostmask unit tests for Hostmask
+func TestHostmask(t *testing.T) {
+	assert.Equal(t, "0.0.0.255", ipx.Hostmask(cidr("10.0.0.0/24")).String())
+	assert.Equal(t, "0.0.0.0", ipx.Hostmask(cidr("10.0.0.1/32")).String())
+	assert.Equal(t, "::ffff:ffff:ffff:ffff", ipx.Hostmask(cidr("2001:db8::/64")).String())
+	assert.Nil(t, ipx.Hostmask(nil))
+	assert.Nil(t, ipx.Hostmask(&net.IPNet{IP: net.IPv4zero, Mask: net.IPv4Mask(255, 0, 255, 0)}))
+}
+
+// TestPrefixForHosts unit tests for PrefixForHosts
+func TestPrefixForHosts(t *testing.T) {
+	for _, c := range []struct {
+		n        ipx.Uint128
+		v6       bool
+		expected int
+	}{
+		{ipx.Uint128{}, false, 32},
+		{ipx.Uint128{Lo: 1}, false, 32},
+		{ipx.Uint128{Lo: 2}, false, 31},
+		{ipx.Uint128{Lo: 3}, false, 29},
+		{ipx.Uint128{Lo: 254}, false, 24},
+		{ipx.Uint128{Lo: 255}, false, 23},
+		{ipx.Uint128{Lo: 1<<32 - 2}, false, 0},
+		{ipx.Uint128{Lo: 2}, true, 127},
+		{ipx.Uint128{Hi: 1}, true, 63},
+		{ipx.Uint128{Hi: maxUint64, Lo: maxUint64 - 1}, true, 0},
+	} {
+		got, err := ipx.PrefixForHosts(c.n, c.v6)
+		if assert.NoError(t, err, c.n) {
+			assert.Equal(t, c.expected, got, c.n)
+		}
+	}
+
+	_, err := ipx.PrefixForHosts(ipx.Uint128{Lo: 1<<32 - 1}, false)
+	assert.ErrorIs(t, err, ipx.ErrOverflow)
+	_, err = ipx.PrefixForHosts(ipx.Uint128{Hi: maxUint64, Lo: maxUint64}, true)
+	assert.ErrorIs(t, err, ipx.ErrOverflow)
+}
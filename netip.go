@@ -0,0 +1,463 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
+)
+
+// AddrRange represents [first, last] IP range of netip.Addr values.
+// It is the same as Range but comparable, so it can be used as a map key.
+//
+// Note, first and last addresses are included into the IP range!
+// If `first > last` IP range considered as empty.
+type AddrRange struct {
+	First netip.Addr
+	Last  netip.Addr
+}
+
+// NewAddrRange is helper function to construct IP range.
+func NewAddrRange(first, last netip.Addr) AddrRange {
+	return AddrRange{
+		First: first,
+		Last:  last,
+	}
+}
+
+// AddrRangeFromPrefix returns the IP range for the given prefix.
+// Returns the zero AddrRange on bad input.
+func AddrRangeFromPrefix(p netip.Prefix) AddrRange {
+	key, v6, err := prefixKey(p)
+	if err != nil {
+		return AddrRange{} // bad prefix
+	}
+
+	if v6 {
+		return NewAddrRange(
+			storeAddr(key.addr, true),
+			storeAddr(key.addr.Or(key.mask().Not()), true))
+	}
+
+	n := key.narrow()
+	return NewAddrRange(
+		storeAddr(Uint128{Lo: uint64(n.addr)}, false),
+		storeAddr(Uint128{Lo: uint64(n.addr | ^n.mask())}, false))
+}
+
+// AddrRange returns the same IP range of netip.Addr values.
+// IPv4-mapped IPv6 addresses are converted to IPv4.
+func (r Range) AddrRange() AddrRange {
+	first, _ := netip.AddrFromSlice(r.First)
+	last, _ := netip.AddrFromSlice(r.Last)
+	return NewAddrRange(first.Unmap(), last.Unmap())
+}
+
+// Range returns the same IP range of net.IP values.
+func (r AddrRange) Range() Range {
+	return NewRange(r.First.AsSlice(), r.Last.AsSlice())
+}
+
+// String returns the IP range in `first-last` form.
+// Returns empty string for the zero AddrRange.
+func (r AddrRange) String() string {
+	if r == (AddrRange{}) {
+		return ""
+	}
+	return r.First.String() + "-" + r.Last.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String().
+func (r AddrRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The IP range is expected in a form accepted by ParseRange().
+// Empty text is decoded as the zero AddrRange.
+func (r *AddrRange) UnmarshalText(text []byte) error {
+	var out Range
+	if err := out.UnmarshalText(text); err != nil {
+		return err
+	}
+
+	if len(text) == 0 {
+		*r = AddrRange{}
+	} else {
+		*r = out.AddrRange()
+	}
+	return nil
+}
+
+// IsEmpty returns true if the range contains no addresses.
+// Range with bad or mismatched IP addresses is considered as empty.
+func (r AddrRange) IsEmpty() bool {
+	span, _, err := addrRangeSpan(r)
+	return err != nil || span.empty()
+}
+
+// Size returns the number of addresses in the range.
+// The size of entire IPv6 address space is saturated
+// to the maximum value, see Range.Size().
+func (r AddrRange) Size() Uint128 {
+	span, _, err := addrRangeSpan(r)
+	if err != nil || span.empty() {
+		return Uint128{} // empty range
+	}
+
+	d := span.last.Sub(span.first)
+	if d.Equals(u128.Max()) {
+		return d // saturated
+	}
+	return d.Add64(1)
+}
+
+// Contains returns true if the range contains the IP address.
+func (r AddrRange) Contains(addr netip.Addr) bool {
+	span, v6, err := addrRangeSpan(r)
+	if err != nil {
+		return false // bad range
+	}
+
+	u, uv6, err := addrKey(addr)
+	if err != nil || v6 != uv6 {
+		return false // bad address or version mismatch
+	}
+
+	return span.first.Cmp(u) <= 0 && u.Cmp(span.last) <= 0
+}
+
+// Summarize returns a series of prefixes which cover the range.
+func (r AddrRange) Summarize() ([]netip.Prefix, error) {
+	return SummarizeAddrRange(r.First, r.Last)
+}
+
+// Addresses returns all of the addresses within the range.
+func (r AddrRange) Addresses() *IPIter {
+	span, v6, err := addrRangeSpan(r)
+	if err != nil {
+		return new(IPIter)
+	}
+	return span.addresses(v6)
+}
+
+// Addr returns the most recent IP as netip.Addr.
+// Returns the zero Addr if there is no current IP.
+func (i *IPIter) Addr() netip.Addr {
+	addr, _ := netip.AddrFromSlice(i.ip)
+	if i.flags&ipIterFlagV6 == 0 {
+		return addr.Unmap() // IPv4 is stored in 16 bytes
+	}
+	return addr
+}
+
+// Prefix returns the most recent network as netip.Prefix.
+// Returns the zero Prefix if there is no current network.
+func (n *NetIter) Prefix() netip.Prefix {
+	if n.net == nil {
+		return netip.Prefix{}
+	}
+
+	addr := n.ips.Addr()
+	ones, bits := n.net.Mask.Size()
+	if addr.Is4() && bits == 8*net.IPv6len {
+		ones -= 96 // IPv4-mapped IPv6 network
+	}
+	return netip.PrefixFrom(addr, ones)
+}
+
+// CollapsePrefixes combines prefixes into their closest available parent.
+// The same as Collapse() but for netip.Prefix. Invalid prefixes are ignored.
+func CollapsePrefixes(toMerge []netip.Prefix) []netip.Prefix {
+	var (
+		four []ip4Net
+		six  []ip6Net
+	)
+	for _, p := range toMerge {
+		key, v6, err := prefixKey(p)
+		if err != nil {
+			continue // ignore bad prefix
+		}
+		if v6 {
+			six = append(six, key)
+			continue
+		}
+		four = append(four, key.narrow())
+	}
+	return append(collapse4(four).asPrefixes(), collapse6(six).asPrefixes()...)
+}
+
+// ExcludePrefix returns a list of prefixes representing the address block when `b` is removed from `a`.
+// The same as Exclude() but for netip.Prefix.
+func ExcludePrefix(a, b netip.Prefix) []netip.Prefix {
+	ka, av6, err := prefixKey(a)
+	if err != nil {
+		return []netip.Prefix{a}
+	}
+	kb, bv6, err := prefixKey(b)
+	if err != nil || av6 != bv6 || kb.prefix < ka.prefix || !kb.subnetOf(ka) {
+		return []netip.Prefix{a}
+	}
+	if ka == kb {
+		return nil // nothing left
+	}
+
+	if av6 {
+		return exclude6(ka, kb).asPrefixes()
+	}
+	return exclude4(ka.narrow(), kb.narrow()).asPrefixes()
+}
+
+// SplitPrefix splits a prefix into smaller prefixes according to the new prefix length provided.
+// The same as Split() but for netip.Prefix, use NetIter.Prefix() to get values.
+func SplitPrefix(p netip.Prefix, newPrefix int) *NetIter {
+	key, v6, err := prefixKey(p)
+	if err != nil || int(key.prefix) > newPrefix {
+		return new(NetIter)
+	}
+
+	if v6 {
+		if newPrefix > 8*net.IPv6len {
+			return new(NetIter)
+		}
+		return split6(key.addr, int(key.prefix), 8*net.IPv6len, newPrefix)
+	}
+
+	if newPrefix > 8*net.IPv4len {
+		return new(NetIter)
+	}
+	return split4(key.narrow().addr, int(key.prefix), 8*net.IPv4len, newPrefix)
+}
+
+// PrefixAddresses returns all of the addresses within a prefix.
+// The same as Addresses() but for netip.Prefix, use IPIter.Addr() to get values.
+func PrefixAddresses(p netip.Prefix) *IPIter {
+	key, v6, err := prefixKey(p)
+	if err != nil {
+		return new(IPIter)
+	}
+
+	if v6 {
+		return addresses6(key.addr, int(key.prefix), 8*net.IPv6len)
+	}
+	return addresses4(key.narrow().addr, int(key.prefix), 8*net.IPv4len)
+}
+
+// PrefixHosts returns all of the usable addresses within a prefix except the network itself address and the broadcast address.
+// The same as Hosts() but for netip.Prefix, use IPIter.Addr() to get values.
+func PrefixHosts(p netip.Prefix) *IPIter {
+	key, v6, err := prefixKey(p)
+	if err != nil {
+		return new(IPIter)
+	}
+
+	if v6 {
+		return hosts6(key.addr, int(key.prefix), 8*net.IPv6len)
+	}
+	return hosts4(key.narrow().addr, int(key.prefix), 8*net.IPv4len)
+}
+
+// SummarizeAddrRange returns a series of prefixes which cover the range
+// between the first and last addresses, inclusive.
+// The same as SummarizeRange() but for netip.Addr.
+func SummarizeAddrRange(first, last netip.Addr) ([]netip.Prefix, error) {
+	f, fv6, err := addrKey(first)
+	if err != nil {
+		return nil, fmt.Errorf("%w: first", err)
+	}
+	l, lv6, err := addrKey(last)
+	if err != nil {
+		return nil, fmt.Errorf("%w: last", err)
+	}
+	if fv6 != lv6 {
+		return nil, ErrVersionMismatch
+	}
+
+	if fv6 {
+		return summarizeRange6(f, l).asPrefixes(), nil
+	}
+	return summarizeRange4(uint32(f.Lo), uint32(l.Lo)).asPrefixes(), nil
+}
+
+// SupernetPrefix returns a supernet for the provided prefix with the specified prefix length.
+// The same as Supernet() but for netip.Prefix. Returns the zero Prefix on bad input.
+func SupernetPrefix(p netip.Prefix, targetPrefixLen int) netip.Prefix {
+	key, v6, err := prefixKey(p)
+	if err != nil || targetPrefixLen < 0 || targetPrefixLen > int(key.prefix) {
+		return netip.Prefix{} // invalid target prefix length
+	}
+
+	key = key.truncate(uint8(targetPrefixLen))
+	if v6 {
+		return key.asPrefix()
+	}
+	return key.narrow().asPrefix()
+}
+
+// BroadcastAddr returns the broadcast IP address for the provided prefix.
+// The same as Broadcast() but for netip.Prefix. Returns the zero Addr on bad input.
+func BroadcastAddr(p netip.Prefix) netip.Addr {
+	return AddrRangeFromPrefix(p).Last
+}
+
+// NextAddr returns the next IP address.
+// The same as NextIP() but for netip.Addr. Returns the zero Addr on bad input.
+func NextAddr(addr netip.Addr, step int) netip.Addr {
+	if step == 0 {
+		return addr // the same address
+	}
+
+	u, v6, err := addrKey(addr)
+	if err != nil {
+		return netip.Addr{} // bad address
+	}
+
+	if step > 0 {
+		u = u.Add64(uint64(+step))
+	} else {
+		u = u.Sub64(uint64(-step))
+	}
+	return storeAddr(u, v6)
+}
+
+// NextPrefix returns the next prefix of the same length.
+// The same as NextNetwork() but for netip.Prefix. Returns the zero Prefix on bad input.
+func NextPrefix(p netip.Prefix, step int) netip.Prefix {
+	if step == 0 {
+		return p // prefix is the same
+	}
+
+	key, v6, err := prefixKey(p)
+	if err != nil {
+		return netip.Prefix{} // bad prefix
+	}
+
+	if v6 {
+		suffix := uint(8*net.IPv6len) - uint(key.prefix)
+		if step > 0 {
+			key.addr = key.addr.Add(Uint128{Lo: uint64(+step)}.Lsh(suffix))
+		} else {
+			key.addr = key.addr.Sub(Uint128{Lo: uint64(-step)}.Lsh(suffix))
+		}
+		return key.asPrefix()
+	}
+
+	n := key.narrow()
+	suffix := uint(8*net.IPv4len) - uint(n.prefix)
+	if step > 0 {
+		n.addr += uint32(+step << suffix)
+	} else {
+		n.addr -= uint32(-step << suffix)
+	}
+	return n.asPrefix()
+}
+
+// ReversePTRAddr returns the name of the reverse DNS PTR record for the given IP address.
+// The same as ReversePTR() but for netip.Addr.
+func ReversePTRAddr(addr netip.Addr) string {
+	return ReversePTR(addr.Unmap().AsSlice())
+}
+
+// prefixKey converts the prefix into the trie key, see tableKey().
+// IPv4-mapped IPv6 prefixes are converted to IPv4.
+func prefixKey(p netip.Prefix) (key ip6Net, v6 bool, err error) {
+	if !p.IsValid() {
+		return key, false, ErrInvalidNetwork
+	}
+
+	addr, ones := p.Addr(), p.Bits()
+	if addr.Is4In6() && ones >= 96 {
+		addr, ones = addr.Unmap(), ones-96
+	}
+
+	if addr.Is4() {
+		a := addr.As4()
+		n := ip4Net{addr: load32(a[:]), prefix: uint8(ones)}
+		n.addr &= n.mask()
+		return n.wide(), false, nil
+	}
+
+	a := addr.As16()
+	n := ip6Net{addr: load128(a[:]), prefix: uint8(ones)}
+	n.addr = n.addr.And(n.mask())
+	return n, true, nil
+}
+
+// addrKey reads IPv4 or IPv6 address as 128 bits integer, see loadIP().
+// IPv4-mapped IPv6 addresses are converted to IPv4.
+func addrKey(addr netip.Addr) (u Uint128, v6 bool, err error) {
+	if !addr.IsValid() {
+		return u, false, ErrInvalidIP
+	}
+
+	addr = addr.Unmap()
+	if addr.Is4() {
+		a := addr.As4()
+		return Uint128{Lo: uint64(load32(a[:]))}, false, nil
+	}
+
+	a := addr.As16()
+	return load128(a[:]), true, nil
+}
+
+// storeAddr converts 128 bits integer back to IPv4 or IPv6 address, see storeIP().
+func storeAddr(u Uint128, v6 bool) netip.Addr {
+	if v6 {
+		var a [net.IPv6len]byte
+		store128(u, a[:])
+		return netip.AddrFrom16(a)
+	}
+
+	var a [net.IPv4len]byte
+	store32(uint32(u.Lo), a[:])
+	return netip.AddrFrom4(a)
+}
+
+// addrRangeSpan returns the span of IP addresses of the range, see rangeSpan().
+func addrRangeSpan(r AddrRange) (span ipSpan, v6 bool, err error) {
+	first, firstV6, err := addrKey(r.First)
+	if err != nil {
+		return span, false, err
+	}
+	last, lastV6, err := addrKey(r.Last)
+	if err != nil {
+		return span, false, err
+	}
+	if firstV6 != lastV6 {
+		return span, false, ErrVersionMismatch
+	}
+
+	return ipSpan{first: first, last: last}, firstV6, nil
+}
+
+func (n ip4Net) asPrefix() netip.Prefix {
+	return netip.PrefixFrom(storeAddr(Uint128{Lo: uint64(n.addr)}, false), int(n.prefix))
+}
+
+func (n ip6Net) asPrefix() netip.Prefix {
+	return netip.PrefixFrom(storeAddr(n.addr, true), int(n.prefix))
+}
+
+func (n ip4Nets) asPrefixes() []netip.Prefix {
+	if len(n) == 0 {
+		return nil
+	}
+	out := make([]netip.Prefix, 0, len(n))
+	for _, m := range n {
+		out = append(out, m.asPrefix())
+	}
+	return out
+}
+
+func (n ip6Nets) asPrefixes() []netip.Prefix {
+	if len(n) == 0 {
+		return nil
+	}
+	out := make([]netip.Prefix, 0, len(n))
+	for _, m := range n {
+		out = append(out, m.asPrefix())
+	}
+	return out
+}
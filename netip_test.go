@@ -0,0 +1,197 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleSplitPrefix is an example of netip.Prefix iteration
+func ExampleSplitPrefix() {
+	seen := make(map[netip.Prefix]bool)
+	iter := ipx.SplitPrefix(netip.MustParsePrefix("10.0.0.0/24"), 26)
+	for iter.Next() {
+		seen[iter.Prefix()] = true
+	}
+	fmt.Println(len(seen), seen[netip.MustParsePrefix("10.0.0.192/26")])
+	// Output:
+	// 4 true
+}
+
+// prefixStrings converts the prefixes into strings.
+func prefixStrings(prefixes []netip.Prefix) []string {
+	var out []string
+	for _, p := range prefixes {
+		out = append(out, p.String())
+	}
+	return out
+}
+
+// addrStrings collects all the rest addresses of the iterator.
+func addrStrings(iter *ipx.IPIter) []string {
+	var out []string
+	for iter.Next() {
+		out = append(out, iter.Addr().String())
+	}
+	return out
+}
+
+// TestNetipPrefixes unit tests for netip.Prefix functions
+func TestNetipPrefixes(tt *testing.T) {
+	pfx := netip.MustParsePrefix
+
+	tt.Run("collapse", func(t *testing.T) {
+		got := ipx.CollapsePrefixes([]netip.Prefix{
+			pfx("10.0.0.0/25"),
+			pfx("10.0.0.128/25"),
+			pfx("2001:db8::/65"),
+			pfx("2001:db8::8000:0:0:0/65"),
+			{}, // ignored
+		})
+		assert.Equal(t, []string{"10.0.0.0/24", "2001:db8::/64"}, prefixStrings(got))
+	})
+
+	tt.Run("exclude", func(t *testing.T) {
+		for _, c := range []struct {
+			a, b     string
+			expected []string
+		}{
+			{"10.1.1.0/24", "10.1.1.0/26", []string{"10.1.1.128/25", "10.1.1.64/26"}},
+			{"10.1.1.0/24", "10.0.1.0/26", []string{"10.1.1.0/24"}},
+			{"10.1.1.0/24", "2001:db8::1/128", []string{"10.1.1.0/24"}},
+			{"10.1.1.0/24", "10.1.1.0/24", nil},
+			{"2001:db8::/124", "2001:db8::8/126", []string{"2001:db8::/125", "2001:db8::c/126"}},
+		} {
+			assert.Equal(t, c.expected, prefixStrings(ipx.ExcludePrefix(pfx(c.a), pfx(c.b))), c.a+" "+c.b)
+		}
+	})
+
+	tt.Run("split", func(t *testing.T) {
+		var got []string
+		for iter := ipx.SplitPrefix(pfx("2001:db8::/126"), 127); iter.Next(); {
+			got = append(got, iter.Prefix().String())
+		}
+		assert.Equal(t, []string{"2001:db8::/127", "2001:db8::2/127"}, got)
+
+		assert.False(t, ipx.SplitPrefix(pfx("10.0.0.0/24"), 23).Next())
+		assert.False(t, ipx.SplitPrefix(pfx("10.0.0.0/24"), 33).Next())
+		assert.False(t, ipx.SplitPrefix(netip.Prefix{}, 24).Next())
+		assert.Equal(t, netip.Prefix{}, new(ipx.NetIter).Prefix())
+	})
+
+	tt.Run("addresses", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"},
+			addrStrings(ipx.PrefixAddresses(pfx("10.0.0.2/30"))))
+		assert.Equal(t, []string{"2001:db8::1", "2001:db8::2"},
+			addrStrings(ipx.PrefixHosts(pfx("2001:db8::/126"))))
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"},
+			addrStrings(ipx.PrefixHosts(pfx("::ffff:10.0.0.0/126"))))
+
+		// the end of address space
+		last := addrStrings(ipx.PrefixAddresses(pfx("ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff0/124")))
+		require.Len(t, last, 16)
+		assert.Equal(t, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff0", last[0])
+		assert.Equal(t, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", last[15])
+		assert.Equal(t, []string{"255.255.255.254", "255.255.255.255"},
+			addrStrings(ipx.PrefixAddresses(pfx("255.255.255.254/31"))))
+	})
+
+	tt.Run("supernet", func(t *testing.T) {
+		assert.Equal(t, pfx("10.0.0.0/8"), ipx.SupernetPrefix(pfx("10.1.2.0/24"), 8))
+		assert.Equal(t, pfx("2001:db8::/32"), ipx.SupernetPrefix(pfx("2001:db8:1::/48"), 32))
+		assert.False(t, ipx.SupernetPrefix(pfx("10.1.2.0/24"), 25).IsValid())
+		assert.False(t, ipx.SupernetPrefix(pfx("10.1.2.0/24"), -1).IsValid())
+	})
+
+	tt.Run("broadcast", func(t *testing.T) {
+		assert.Equal(t, netip.MustParseAddr("10.1.2.255"), ipx.BroadcastAddr(pfx("10.1.2.3/24")))
+		assert.Equal(t, netip.MustParseAddr("2001:db8::ffff"), ipx.BroadcastAddr(pfx("2001:db8::/112")))
+		assert.False(t, ipx.BroadcastAddr(netip.Prefix{}).IsValid())
+	})
+
+	tt.Run("next", func(t *testing.T) {
+		assert.Equal(t, pfx("10.0.1.0/24"), ipx.NextPrefix(pfx("10.0.0.0/24"), 1))
+		assert.Equal(t, pfx("9.255.255.0/24"), ipx.NextPrefix(pfx("10.0.0.0/24"), -1))
+		assert.Equal(t, pfx("2001:db8:0:2::/64"), ipx.NextPrefix(pfx("2001:db8::/64"), 2))
+		assert.Equal(t, pfx("2001:db8::/64"), ipx.NextPrefix(pfx("2001:db8::/64"), 0))
+		assert.False(t, ipx.NextPrefix(netip.Prefix{}, 1).IsValid())
+	})
+}
+
+// TestNetipAddrs unit tests for netip.Addr functions
+func TestNetipAddrs(tt *testing.T) {
+	addr := netip.MustParseAddr
+
+	tt.Run("next", func(t *testing.T) {
+		assert.Equal(t, addr("10.0.1.0"), ipx.NextAddr(addr("10.0.0.255"), 1))
+		assert.Equal(t, addr("255.255.255.255"), ipx.NextAddr(addr("0.0.0.0"), -1))
+		assert.Equal(t, addr("2001:db8::1:0"), ipx.NextAddr(addr("2001:db8::ffff"), 1))
+		assert.False(t, ipx.NextAddr(netip.Addr{}, 1).IsValid())
+	})
+
+	tt.Run("reverse_ptr", func(t *testing.T) {
+		assert.Equal(t, "4.3.2.1.in-addr.arpa", ipx.ReversePTRAddr(addr("1.2.3.4")))
+		assert.Equal(t, "4.3.2.1.in-addr.arpa", ipx.ReversePTRAddr(addr("::ffff:1.2.3.4")))
+		assert.Equal(t, ipx.ReversePTR(net.ParseIP("2001:db8::1")), ipx.ReversePTRAddr(addr("2001:db8::1")))
+		assert.Equal(t, "", ipx.ReversePTRAddr(netip.Addr{}))
+	})
+
+	tt.Run("summarize", func(t *testing.T) {
+		got, err := ipx.SummarizeAddrRange(addr("10.0.0.1"), addr("10.0.0.6"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}, prefixStrings(got))
+
+		got, err = ipx.SummarizeAddrRange(addr("::"), addr("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"::/0"}, prefixStrings(got))
+
+		_, err = ipx.SummarizeAddrRange(addr("10.0.0.1"), addr("2001:db8::"))
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+		_, err = ipx.SummarizeAddrRange(netip.Addr{}, addr("10.0.0.1"))
+		assert.ErrorIs(t, err, ipx.ErrInvalidIP)
+	})
+}
+
+// TestAddrRange unit tests for AddrRange
+func TestAddrRange(t *testing.T) {
+	addr := netip.MustParseAddr
+
+	r := ipx.NewAddrRange(addr("10.0.0.1"), addr("10.0.0.3"))
+	assert.Equal(t, "10.0.0.1-10.0.0.3", r.String())
+	assert.False(t, r.IsEmpty())
+	assert.Equal(t, ipx.Uint128{Lo: 3}, r.Size())
+	assert.True(t, r.Contains(addr("10.0.0.2")))
+	assert.False(t, r.Contains(addr("10.0.0.4")))
+	assert.False(t, r.Contains(addr("2001:db8::")))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, addrStrings(r.Addresses()))
+
+	// comparable and convertible
+	assert.Equal(t, r, r.Range().AddrRange())
+	assert.Equal(t, r, ipx.NewRange(net.ParseIP("::ffff:10.0.0.1"), net.ParseIP("10.0.0.3")).AddrRange())
+	assert.Equal(t, map[ipx.AddrRange]int{r: 1}, map[ipx.AddrRange]int{ipx.NewAddrRange(addr("10.0.0.1"), addr("10.0.0.3")): 1})
+
+	p := ipx.AddrRangeFromPrefix(netip.MustParsePrefix("2001:db8::/126"))
+	assert.Equal(t, "2001:db8::-2001:db8::3", p.String())
+	sum, err := p.Summarize()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::/126"}, prefixStrings(sum))
+
+	assert.True(t, ipx.NewAddrRange(addr("10.0.0.3"), addr("10.0.0.1")).IsEmpty())
+	assert.True(t, ipx.NewAddrRange(addr("10.0.0.1"), addr("2001:db8::")).IsEmpty())
+	assert.False(t, ipx.NewAddrRange(addr("10.0.0.3"), addr("10.0.0.1")).Addresses().Next())
+
+	var u ipx.AddrRange
+	require.NoError(t, u.UnmarshalText([]byte("10.0.0.1-3")))
+	assert.Equal(t, r, u)
+	require.NoError(t, u.UnmarshalText(nil))
+	assert.Equal(t, ipx.AddrRange{}, u)
+	assert.Error(t, u.UnmarshalText([]byte("foo")))
+	text, err := r.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1-10.0.0.3", string(text))
+}
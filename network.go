@@ -1,15 +1,22 @@
 package ipx
 
 import (
+	"fmt"
 	"net"
+	"strings"
 )
 
 // Supernet returns a supernet for the provided network with the specified prefix length.
 // targetPrefixLen is the number of "one" bits in new supernet mask.
+// Host bits of the network are ignored.
 func Supernet(network *net.IPNet, targetPrefixLen int) *net.IPNet {
-	if network == nil {
+	ipNet := network
+	if network = canonical(network); network == nil {
 		return nil // no network, no supernet
 	}
+	if len(network.Mask) < len(ipNet.Mask) {
+		targetPrefixLen -= 96 // IPv4-mapped IPv6 network
+	}
 
 	ones, bits := network.Mask.Size()
 	if targetPrefixLen < 0 ||
@@ -32,27 +39,24 @@ func Supernet(network *net.IPNet, targetPrefixLen int) *net.IPNet {
 	}
 
 	// IPv6
-	if v6 := network.IP.To16(); v6 != nil {
-		u := load128(v6)
-		mask := Uint128{Lo: 1}.
-			Lsh(uint(targetPrefixLen)).
-			Sub64(1).
-			Lsh(uint(bits - targetPrefixLen))
-
-		out := make(net.IP, net.IPv6len)
-		store128(u.And(mask), out)
-		return &net.IPNet{
-			IP:   out,
-			Mask: net.CIDRMask(targetPrefixLen, bits),
-		}
+	u := load128(network.IP)
+	mask := Uint128{Lo: 1}.
+		Lsh(uint(targetPrefixLen)).
+		Sub64(1).
+		Lsh(uint(bits - targetPrefixLen))
+
+	out := make(net.IP, net.IPv6len)
+	store128(u.And(mask), out)
+	return &net.IPNet{
+		IP:   out,
+		Mask: net.CIDRMask(targetPrefixLen, bits),
 	}
-
-	return nil // bad input address length
 }
 
 // Broadcast returns the broadcast IP address for the provided network.
+// Host bits of the network are ignored.
 func Broadcast(network *net.IPNet) net.IP {
-	if network == nil {
+	if network = canonical(network); network == nil {
 		return nil // no network, no address
 	}
 
@@ -69,22 +73,22 @@ func Broadcast(network *net.IPNet) net.IP {
 	}
 
 	// IPv6
-	if v6 := network.IP.To16(); v6 != nil {
-		u := load128(network.IP)
-		bcmask := Uint128{Lo: 1}.
-			Lsh(uint(bits - ones)).
-			Sub64(1)
-
-		out := make(net.IP, net.IPv6len)
-		store128(u.Or(bcmask), out)
-		return out
-	}
-
-	return nil // bad input address length
+	u := load128(network.IP)
+	bcmask := Uint128{Lo: 1}.
+		Lsh(uint(bits - ones)).
+		Sub64(1)
+
+	out := make(net.IP, net.IPv6len)
+	store128(u.Or(bcmask), out)
+	return out
 }
 
 // IsSubnet returns whether b is a subnet of a.
+// Host bits of the networks are ignored.
 func IsSubnet(a, b *net.IPNet) bool {
+	if a, b = canonical(a), canonical(b); a == nil || b == nil {
+		return false // bad networks
+	}
 	if !a.Contains(b.IP) {
 		return false
 	}
@@ -102,16 +106,20 @@ func IsSupernet(a, b *net.IPNet) bool {
 // NextNetwork returns the next network of the same mask.
 // The step argument can be positive returning next network,
 // or negative returning previous network.
+// Host bits of the network are ignored.
 func NextNetwork(network *net.IPNet, step int) *net.IPNet {
 	if network == nil || step == 0 {
 		return network // network is the same
 	}
+	if network = canonical(network); network == nil {
+		return nil // bad network
+	}
+
+	ones, bits := network.Mask.Size()
+	suffix := uint(bits - ones)
 
 	// IPv4
 	if v4 := network.IP.To4(); v4 != nil {
-		ones, bits := network.Mask.Size()
-		suffix := uint(bits - ones)
-
 		u := load32(v4)
 		if step > 0 {
 			u += uint32(+step << suffix)
@@ -123,29 +131,75 @@ func NextNetwork(network *net.IPNet, step int) *net.IPNet {
 		store32(u, out)
 		return &net.IPNet{
 			IP:   out,
-			Mask: network.Mask, // shared
+			Mask: network.Mask,
 		}
 	}
 
 	// IPv6
-	if v6 := network.IP.To16(); v6 != nil {
-		ones, bits := network.Mask.Size()
-		suffix := uint(bits - ones)
+	u := load128(network.IP)
+	if step > 0 {
+		u = u.Add(Uint128{Lo: uint64(+step)}.Lsh(suffix))
+	} else {
+		u = u.Sub(Uint128{Lo: uint64(-step)}.Lsh(suffix))
+	}
 
-		u := load128(v6)
-		if step > 0 {
-			u = u.Add(Uint128{Lo: uint64(+step)}.Lsh(suffix))
-		} else {
-			u = u.Sub(Uint128{Lo: uint64(-step)}.Lsh(suffix))
-		}
+	out := make(net.IP, net.IPv6len)
+	store128(u, out)
+	return &net.IPNet{
+		IP:   out,
+		Mask: network.Mask,
+	}
+}
 
-		out := make(net.IP, net.IPv6len)
-		store128(u, out)
-		return &net.IPNet{
-			IP:   out,
-			Mask: network.Mask, // shared
-		}
+// ParseNetwork parses the network in CIDR notation.
+// In strict mode ErrInvalidNetwork is returned if host bits are set
+// (e.g. `10.0.0.1/24`), otherwise host bits are silently cleared.
+// The result is canonical, see Canonicalize().
+func ParseNetwork(s string, strict bool) (*net.IPNet, error) {
+	ip, network, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidNetwork, s)
+	}
+	if strict && !ip.Equal(network.IP) {
+		return nil, fmt.Errorf("%w: %q has host bits set", ErrInvalidNetwork, s)
+	}
+	return Canonicalize(network)
+}
+
+// Canonicalize returns the normalized copy of the network:
+// IPv4 networks (including IPv4-mapped IPv6 networks of 16 bytes address and mask)
+// have 4 bytes address and mask,
+// IPv6 networks have 16 bytes address and mask, host bits are cleared.
+// ErrInvalidNetwork is returned for non-contiguous masks
+// or masks not matching the address length.
+func Canonicalize(network *net.IPNet) (*net.IPNet, error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, network)
+	}
+	return keyNet(key, v6), nil
+}
+
+// Validate returns ErrInvalidNetwork if the network is not well-formed,
+// see Canonicalize(), or has host bits set.
+func Validate(network *net.IPNet) error {
+	key, _, err := tableKey(network)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, network)
+	}
+	ip, _, _ := ipKey(network.IP)
+	if ip.addr != key.addr {
+		return fmt.Errorf("%w: %s has host bits set", ErrInvalidNetwork, network)
 	}
+	return nil
+}
 
-	return nil // bad input address length
+// canonical returns the canonical copy of the network, see Canonicalize(),
+// or nil if the network is not well-formed.
+func canonical(network *net.IPNet) *net.IPNet {
+	out, err := Canonicalize(network)
+	if err != nil {
+		return nil
+	}
+	return out
 }
@@ -293,3 +293,171 @@ func cidr(cidrS string) *net.IPNet {
 	_, ipNet, _ := net.ParseCIDR(cidrS)
 	return ipNet
 }
+
+// ExampleParseNetwork is an example of strict network parsing
+func ExampleParseNetwork() {
+	_, err := ipx.ParseNetwork("10.0.0.1/24", true)
+	fmt.Println(err)
+	network, _ := ipx.ParseNetwork("10.0.0.1/24", false)
+	fmt.Println(network)
+	// Output:
+	// invalid IP network: "10.0.0.1/24" has host bits set
+	// 10.0.0.0/24
+}
+
+// TestParseNetwork unit tests for ParseNetwork
+func TestParseNetwork(t *testing.T) {
+	for _, c := range []struct {
+		s        string
+		strict   bool
+		expected string
+	}{
+		{"10.0.0.0/24", true, "10.0.0.0/24"},
+		{" 10.0.0.1/24 ", false, "10.0.0.0/24"},
+		{"::ffff:10.0.0.0/120", true, "10.0.0.0/24"},
+		{"2001:db8::/32", true, "2001:db8::/32"},
+		{"2001:db8::1/32", false, "2001:db8::/32"},
+	} {
+		network, err := ipx.ParseNetwork(c.s, c.strict)
+		if assert.NoError(t, err, c.s) {
+			assert.Equal(t, c.expected, network.String(), c.s)
+		}
+	}
+
+	network, err := ipx.ParseNetwork("10.0.0.0/24", true)
+	require.NoError(t, err)
+	assert.Len(t, network.IP, net.IPv4len)
+
+	for _, s := range []string{"10.0.0.1/24", "2001:db8::1/32", "10.0.0.0/33", "10.0.0.0", "foo/8"} {
+		_, err := ipx.ParseNetwork(s, true)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork, s)
+	}
+}
+
+// TestCanonicalize unit tests for Canonicalize and Validate
+func TestCanonicalize(tt *testing.T) {
+	tt.Run("valid", func(t *testing.T) {
+		for _, c := range []struct {
+			network  *net.IPNet
+			expected string
+			valid    bool
+		}{
+			{cidr("10.0.0.0/8"), "10.0.0.0/8", true},
+			{&net.IPNet{IP: net.ParseIP("10.1.2.3"), Mask: net.CIDRMask(8, 32)}, "10.0.0.0/8", false},
+			{&net.IPNet{IP: net.ParseIP("10.1.2.3"), Mask: net.CIDRMask(104, 128)}, "10.0.0.0/8", false},
+			{&net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(104, 128)}, "10.0.0.0/8", true},
+			{&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(64, 128)}, "2001:db8::/64", false},
+		} {
+			network, err := ipx.Canonicalize(c.network)
+			if assert.NoError(t, err, c.expected) {
+				assert.Equal(t, c.expected, network.String())
+				assert.Equal(t, len(network.IP), len(network.Mask))
+				assert.NoError(t, ipx.Validate(network))
+			}
+			if c.valid {
+				assert.NoError(t, ipx.Validate(c.network), c.network)
+			} else {
+				assert.ErrorIs(t, ipx.Validate(c.network), ipx.ErrInvalidNetwork, c.network)
+			}
+		}
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		for _, network := range []*net.IPNet{
+			nil,
+			{IP: net.ParseIP("10.0.0.0"), Mask: net.IPv4Mask(255, 0, 255, 0)}, // non-contiguous
+			{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(64, 128)},        // too short for IPv4
+			{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(24, 32)},       // too short for IPv6
+			{IP: net.IP{1, 2, 3}, Mask: net.CIDRMask(24, 32)},                 // bad address
+			{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(104, 128)},   // IPv4 address, IPv6 mask
+		} {
+			_, err := ipx.Canonicalize(network)
+			assert.ErrorIs(t, err, ipx.ErrInvalidNetwork, network)
+			assert.ErrorIs(t, ipx.Validate(network), ipx.ErrInvalidNetwork, network)
+		}
+	})
+
+	tt.Run("host_bits", func(t *testing.T) {
+		unmasked := &net.IPNet{IP: net.ParseIP("10.0.0.5"), Mask: net.CIDRMask(30, 32)}
+		assert.Equal(t, []string{"10.0.0.4", "10.0.0.5", "10.0.0.6", "10.0.0.7"}, ipStrings(ipx.Addresses(unmasked)))
+		assert.Equal(t, []string{"10.0.0.5", "10.0.0.6"}, ipStrings(ipx.Hosts(unmasked)))
+
+		var got []string
+		for iter := ipx.Split(unmasked, 31); iter.Next(); {
+			got = append(got, iter.Net().String())
+		}
+		assert.Equal(t, []string{"10.0.0.4/31", "10.0.0.6/31"}, got)
+
+		got = nil
+		for iter := ipx.Split(&net.IPNet{IP: net.ParseIP("10.0.0.5"), Mask: net.CIDRMask(126, 128)}, 127); iter.Next(); {
+			got = append(got, iter.Net().String())
+		}
+		assert.Equal(t, []string{"10.0.0.4/31", "10.0.0.6/31"}, got)
+
+		assert.Equal(t, []string{"10.0.0.0/29"}, netStrings(ipx.Collapse([]*net.IPNet{
+			unmasked,
+			{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(30, 32)},
+		})))
+		assert.Equal(t, []string{"10.0.0.0/24"}, netStrings(ipx.Collapse([]*net.IPNet{
+			hostCIDR("::ffff:10.0.0.5/121"),
+			hostCIDR("10.0.0.200/25"),
+		})))
+	})
+
+	tt.Run("entry_points", func(t *testing.T) {
+		assert.Equal(t, "10.0.1.0/24", ipx.NextNetwork(hostCIDR("10.0.0.5/24"), 1).String())
+		assert.Equal(t, "10.0.1.0/24", ipx.NextNetwork(hostCIDR("::ffff:10.0.0.5/120"), 1).String())
+		assert.Equal(t, "2001:db8::/64", ipx.NextNetwork(hostCIDR("2001:db8:0:1::1/64"), -1).String())
+
+		var got []string
+		for iter := ipx.IterNet(hostCIDR("10.0.0.5/24"), 1, hostCIDR("10.0.2.7/24")); iter.Next(); {
+			got = append(got, iter.Net().String())
+		}
+		assert.Equal(t, []string{"10.0.0.0/24", "10.0.1.0/24"}, got)
+
+		assert.True(t, ipx.IsSubnet(hostCIDR("10.0.0.5/8"), hostCIDR("10.1.2.3/16")))
+		assert.True(t, ipx.IsSubnet(cidr("10.0.0.0/8"), hostCIDR("::ffff:10.1.2.3/112")))
+		assert.False(t, ipx.IsSubnet(hostCIDR("10.0.0.5/24"), hostCIDR("10.0.1.5/24")))
+		assert.True(t, ipx.IsSupernet(hostCIDR("10.1.2.3/16"), hostCIDR("10.0.0.5/8")))
+		assert.False(t, ipx.IsSubnet(&net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.IPv4Mask(255, 0, 255, 0)}, cidr("10.0.0.0/24")))
+
+		assert.Equal(t, []string{"10.0.0.4/31"}, netStrings(ipx.Exclude(hostCIDR("10.0.0.5/30"), hostCIDR("10.0.0.7/31"))))
+		assert.Equal(t, []string{"10.0.0.0/25"}, netStrings(ipx.Exclude(hostCIDR("10.0.0.5/24"), hostCIDR("::ffff:10.0.0.200/121"))))
+
+		assert.Equal(t, "10.1.0.0/16", ipx.Supernet(hostCIDR("10.1.2.3/24"), 16).String())
+		assert.Equal(t, "10.1.0.0/16", ipx.Supernet(hostCIDR("::ffff:10.1.2.3/120"), 112).String())
+		assert.Equal(t, "10.1.2.255", ipx.Broadcast(hostCIDR("::ffff:10.1.2.3/120")).String())
+		assert.Equal(t, "0.0.0.255", ipx.Hostmask(hostCIDR("::ffff:10.1.2.3/120")).String())
+
+		first, last := ipx.RangeFromNetwork(hostCIDR("::ffff:10.0.0.5/126"))
+		assert.Equal(t, "10.0.0.4-10.0.0.7", first.String()+"-"+last.String())
+
+		// 16 bytes IPv4 address with 4 bytes mask
+		wide := &net.IPNet{IP: net.ParseIP("10.0.0.5"), Mask: net.CIDRMask(30, 32)}
+		first, last = ipx.RangeFromNetwork(wide)
+		assert.Equal(t, "10.0.0.4-10.0.0.7", first.String()+"-"+last.String())
+		set, err := ipx.NewRangeSetFromNetworks([]*net.IPNet{wide})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.4-10.0.0.7"}, rangeStrings(set.Ranges()))
+		iter, err := ipx.PermuteNetwork(wide, 1)
+		require.NoError(t, err)
+		count := 0
+		for iter.Next() {
+			count++
+		}
+		assert.Equal(t, 4, count)
+
+		subnet, err := ipx.CIDRSubnet(hostCIDR("10.0.0.5/16"), 8, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.2.0/24", subnet.String())
+	})
+}
+
+// hostCIDR parses the network in CIDR notation keeping host bits of the address.
+func hostCIDR(s string) *net.IPNet {
+	ip, ipNet, _ := net.ParseCIDR(s)
+	if len(ipNet.IP) == net.IPv4len {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip, Mask: ipNet.Mask}
+}
@@ -0,0 +1,185 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+	"sort"
+)
+
+// PermIter permits iteration over all addresses of a network, range or set
+// in pseudo-random order. Each address is visited exactly once.
+//
+// The order is defined by a seeded Feistel network permutation
+// of address indexes, so no visited addresses are stored.
+// The same seed always gives the same order.
+type PermIter struct {
+	spans   []ipSpan  // IPv4 spans go first
+	offsets []Uint128 // index of the first address of each span
+	v6      int       // index of the first IPv6 span
+	last    Uint128   // index of the last address
+	empty   bool
+
+	perm feistel
+	pos  Uint128 // next position in the permutation
+	step Uint128 // distance between positions
+	done bool
+
+	ip4 net.IP
+	ip6 net.IP
+	ip  net.IP
+}
+
+// Permute returns an iterator over all addresses of the set in pseudo-random order.
+// The whole IPv6 address space is accepted, but ErrOverflow is returned
+// if the set contains more than 2^128 addresses.
+func Permute(set *RangeSet, seed uint64) (*PermIter, error) {
+	p := &PermIter{
+		ip4:  make(net.IP, net.IPv4len),
+		ip6:  make(net.IP, net.IPv6len),
+		step: Uint128{Lo: 1},
+	}
+
+	spans := append(append([]ipSpan(nil), set.spans(false)...), set.spans(true)...)
+	p.v6 = len(set.spans(false))
+
+	var next Uint128 // index of the next span
+	for i, s := range spans {
+		if i > 0 && next.IsZero() {
+			return nil, ErrOverflow // more than 2^128 addresses
+		}
+		p.offsets = append(p.offsets, next)
+
+		n := s.last.Sub(s.first)
+		last := next.Add(n)
+		if last.Cmp(next) < 0 {
+			return nil, ErrOverflow // more than 2^128 addresses
+		}
+		p.last, next = last, last.Add64(1)
+	}
+
+	p.spans = spans
+	p.empty = len(spans) == 0
+	p.done = p.empty
+	p.perm = newFeistel(p.last.BitLen(), seed)
+	return p, nil
+}
+
+// PermuteNetwork returns an iterator over all addresses of the network in pseudo-random order.
+func PermuteNetwork(network *net.IPNet, seed uint64) (*PermIter, error) {
+	set, err := NewRangeSetFromNetworks([]*net.IPNet{network})
+	if err != nil {
+		return nil, err
+	}
+	return Permute(set, seed)
+}
+
+// PermuteRange returns an iterator over all addresses of the IP range in pseudo-random order.
+func PermuteRange(r Range, seed uint64) (*PermIter, error) {
+	set, err := NewRangeSet(r)
+	if err != nil {
+		return nil, err
+	}
+	return Permute(set, seed)
+}
+
+// Shard restricts the iterator to the index-th of count disjoint subsets
+// of the same permutation, so count workers created with the same seed
+// visit all addresses exactly once together. The iteration is restarted.
+// ErrInvalidShard is returned if count is zero or index is out of range.
+func (p *PermIter) Shard(index, count uint64) error {
+	if count == 0 || index >= count {
+		return fmt.Errorf("%w: %d of %d", ErrInvalidShard, index, count)
+	}
+
+	p.pos = Uint128{Lo: index}
+	p.step = Uint128{Lo: count}
+	p.done = p.empty || p.pos.Cmp(p.last) > 0
+	return nil
+}
+
+// IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
+// It does no allocation.
+func (p *PermIter) IP() net.IP {
+	return p.ip
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (p *PermIter) Next() bool {
+	if p.done {
+		return false
+	}
+
+	// cycle-walk until the index fits into the address space
+	idx := p.perm.permute(p.pos)
+	for idx.Cmp(p.last) > 0 {
+		idx = p.perm.permute(idx)
+	}
+
+	// advance the position
+	if next := p.pos.Add(p.step); next.Cmp(p.pos) < 0 || next.Cmp(p.last) > 0 {
+		p.done = true
+	} else {
+		p.pos = next
+	}
+
+	// find the span containing the index
+	i := sort.Search(len(p.offsets), func(i int) bool {
+		return p.offsets[i].Cmp(idx) > 0
+	}) - 1
+
+	addr := p.spans[i].first.Add(idx.Sub(p.offsets[i]))
+	if i >= p.v6 {
+		store128(addr, p.ip6)
+		p.ip = p.ip6
+	} else {
+		store32(uint32(addr.Lo), p.ip4)
+		p.ip = p.ip4
+	}
+
+	return true
+}
+
+// feistelRounds is the number of Feistel network rounds.
+const feistelRounds = 4
+
+// feistel is a balanced Feistel network permuting
+// integers of the `2*half` bits domain.
+type feistel struct {
+	half uint
+	mask uint64
+	keys [feistelRounds]uint64
+}
+
+// newFeistel returns a Feistel network covering at least `bits` bits domain.
+func newFeistel(bits int, seed uint64) feistel {
+	half := uint(bits+1) / 2
+	if half == 0 {
+		half = 1
+	}
+
+	f := feistel{half: half, mask: maxUint64 >> (64 - half)}
+	for i := range f.keys {
+		seed += 0x9e3779b97f4a7c15
+		f.keys[i] = mix64(seed)
+	}
+
+	return f
+}
+
+// permute returns the permuted value.
+func (f feistel) permute(x Uint128) Uint128 {
+	l := x.Rsh(f.half).Lo & f.mask
+	r := x.Lo & f.mask
+	for _, k := range f.keys {
+		l, r = r, l^(mix64(r^k)&f.mask)
+	}
+
+	return Uint128{Lo: l}.Lsh(f.half).Or(Uint128{Lo: r})
+}
+
+// mix64 is the SplitMix64 finalizer.
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,144 @@
+package ipx_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// permuted returns all addresses visited by the iterator.
+func permuted(iter *ipx.PermIter) []string {
+	var out []string
+	for iter.Next() {
+		out = append(out, iter.IP().String())
+	}
+	return out
+}
+
+// addresses returns all addresses of the iterator in order.
+func addresses(iter *ipx.RangeSetIter) []string {
+	var out []string
+	for iter.Next() {
+		out = append(out, iter.IP().String())
+	}
+	return out
+}
+
+// TestPermute unit tests for Permute
+func TestPermute(tt *testing.T) {
+	tt.Run("network", func(t *testing.T) {
+		for _, s := range []string{"10.0.0.0/32", "10.0.0.0/31", "10.0.0.0/24", "10.0.0.0/21", "2001:db8::/120", "2001:db8::/117"} {
+			iter, err := ipx.PermuteNetwork(cidr(s), 42)
+			require.NoError(t, err)
+			got := permuted(iter)
+
+			set, err := ipx.NewRangeSetFromNetworks([]*net.IPNet{cidr(s)})
+			require.NoError(t, err)
+			expected := addresses(set.Addresses())
+			assert.ElementsMatch(t, expected, got, s)
+			if len(expected) > 16 {
+				assert.NotEqual(t, expected, got, s) // shuffled
+			}
+		}
+	})
+
+	tt.Run("set", func(t *testing.T) {
+		set, err := ipx.NewRangeSet(
+			ipRange("10.0.0.5", "10.0.0.17"),
+			ipRange("10.0.1.0", "10.0.1.0"),
+			ipRange("255.255.255.250", "255.255.255.255"),
+			ipRange("2001:db8::ff", "2001:db8::103"),
+		)
+		require.NoError(t, err)
+
+		iter, err := ipx.Permute(set, 7)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, addresses(set.Addresses()), permuted(iter))
+	})
+
+	tt.Run("seed", func(t *testing.T) {
+		r := ipRange("10.0.0.0", "10.0.3.255")
+		a, err := ipx.PermuteRange(r, 1)
+		require.NoError(t, err)
+		b, err := ipx.PermuteRange(r, 1)
+		require.NoError(t, err)
+		c, err := ipx.PermuteRange(r, 2)
+		require.NoError(t, err)
+
+		pa, pb, pc := permuted(a), permuted(b), permuted(c)
+		assert.Equal(t, pa, pb)
+		assert.NotEqual(t, pa, pc)
+		assert.ElementsMatch(t, pa, pc)
+	})
+
+	tt.Run("shards", func(t *testing.T) {
+		r := ipRange("10.0.0.0", "10.0.2.100")
+		all, err := ipx.PermuteRange(r, 5)
+		require.NoError(t, err)
+		expected := permuted(all)
+
+		seen := make(map[string]int)
+		var total []string
+		for i := uint64(0); i < 7; i++ {
+			iter, err := ipx.PermuteRange(r, 5)
+			require.NoError(t, err)
+			require.NoError(t, iter.Shard(i, 7))
+			for _, ip := range permuted(iter) {
+				seen[ip]++
+				total = append(total, ip)
+			}
+		}
+		assert.ElementsMatch(t, expected, total)
+		for ip, n := range seen {
+			assert.Equal(t, 1, n, ip)
+		}
+
+		iter, err := ipx.PermuteRange(ipRange("10.0.0.0", "10.0.0.1"), 5)
+		require.NoError(t, err)
+		require.NoError(t, iter.Shard(3, 4))
+		assert.False(t, iter.Next()) // shard is empty
+		assert.ErrorIs(t, iter.Shard(4, 4), ipx.ErrInvalidShard)
+		assert.ErrorIs(t, iter.Shard(0, 0), ipx.ErrInvalidShard)
+	})
+
+	tt.Run("huge", func(t *testing.T) {
+		iter, err := ipx.PermuteNetwork(cidr("::/0"), 1)
+		require.NoError(t, err)
+		seen := make(map[string]bool)
+		for i := 0; i < 1000 && iter.Next(); i++ {
+			seen[iter.IP().String()] = true
+		}
+		assert.Len(t, seen, 1000)
+
+		set, err := ipx.NewRangeSetFromNetworks([]*net.IPNet{cidr("::/0"), cidr("10.0.0.0/8")})
+		require.NoError(t, err)
+		_, err = ipx.Permute(set, 1)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+	})
+
+	tt.Run("empty", func(t *testing.T) {
+		iter, err := ipx.Permute(new(ipx.RangeSet), 1)
+		require.NoError(t, err)
+		assert.False(t, iter.Next())
+
+		_, err = ipx.PermuteNetwork(nil, 1)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
+
+// BenchmarkPermute performance benchmarks for PermIter
+func BenchmarkPermute(bb *testing.B) {
+	iter, err := ipx.PermuteNetwork(cidr("10.0.0.0/8"), 1)
+	require.NoError(bb, err)
+
+	bb.ReportAllocs()
+	bb.ResetTimer()
+	for i := 0; i < bb.N; i++ {
+		if !iter.Next() {
+			require.NoError(bb, iter.Shard(0, 1))
+		}
+	}
+}
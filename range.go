@@ -1,7 +1,12 @@
 package ipx
 
 import (
+	"fmt"
 	"net"
+	"strconv"
+	"strings"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
 )
 
 // Range represents [first, last] IP range.
@@ -12,9 +17,12 @@ import (
 // Note, first and last addresses are included into the IP range!
 // If `first == last` IP range contains single address.
 // If `first > last` IP range considered as empty.
+//
+// Range is encoded as text in `first-last` form,
+// see ParseRange() for all supported notations.
 type Range struct {
-	First net.IP `json:"first,string"`
-	Last  net.IP `json:"last,string"`
+	First net.IP
+	Last  net.IP
 }
 
 // NewRange is helper function to construct IP range.
@@ -25,6 +33,135 @@ func NewRange(first net.IP, last net.IP) Range {
 	}
 }
 
+// ParseRange parses the IP range in one of the following notations:
+//   - `10.0.0.1-10.0.0.50` or `2001:db8::1-2001:db8::ff`,
+//   - `10.0.0.1-50` or `10.0.0.1-1.50` replacing the trailing octets of the first address,
+//   - `2001:db8::1-ff` replacing the trailing groups of the first address,
+//   - `10.0.0.0 - 10.0.0.255` as used by whois inetnum objects,
+//   - `10.0.0.0/24` or `2001:db8::/64` CIDR notation,
+//   - `10.0.0.1` single address.
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+
+	// CIDR notation
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: %q", ErrInvalidRange, s)
+		}
+		return NewRangeFromNetwork(network), nil
+	}
+
+	firstStr, lastStr := s, s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		firstStr = strings.TrimSpace(s[:i])
+		lastStr = strings.TrimSpace(s[i+1:])
+	}
+
+	first := net.ParseIP(firstStr)
+	if first == nil {
+		return Range{}, fmt.Errorf("%w: %q bad first address", ErrInvalidRange, s)
+	}
+	if v4 := first.To4(); v4 != nil {
+		first = v4
+	}
+
+	last := net.ParseIP(lastStr)
+	if last == nil {
+		// short form, only trailing part of the last address is provided
+		last = parseRangeSuffix(first, lastStr)
+		if last == nil {
+			return Range{}, fmt.Errorf("%w: %q bad last address", ErrInvalidRange, s)
+		}
+	}
+	if v4 := last.To4(); v4 != nil {
+		last = v4
+	}
+
+	cmp, err := CompareIP(first, last)
+	if err != nil || len(first) != len(last) {
+		return Range{}, fmt.Errorf("%w: %q", ErrVersionMismatch, s)
+	}
+	if cmp > 0 {
+		return Range{}, fmt.Errorf("%w: %q first address is greater than last", ErrInvalidRange, s)
+	}
+
+	return NewRange(first, last), nil
+}
+
+// parseRangeSuffix replaces trailing octets (IPv4) or groups (IPv6)
+// of the first address with the provided ones.
+// Returns nil on bad input.
+func parseRangeSuffix(first net.IP, suffix string) net.IP {
+	if len(first) == net.IPv4len {
+		parts := strings.Split(suffix, ".")
+		if len(parts) >= net.IPv4len {
+			return nil // full address expected
+		}
+
+		last := make(net.IP, net.IPv4len)
+		copy(last, first)
+		for i, p := range parts {
+			u, err := strconv.ParseUint(p, 10, 8)
+			if err != nil {
+				return nil // bad octet
+			}
+			last[net.IPv4len-len(parts)+i] = byte(u)
+		}
+		return last
+	}
+
+	parts := strings.Split(suffix, ":")
+	if len(parts) >= net.IPv6len/2 {
+		return nil // full address expected
+	}
+
+	last := make(net.IP, net.IPv6len)
+	copy(last, first)
+	for i, p := range parts {
+		u, err := strconv.ParseUint(p, 16, 16)
+		if err != nil || len(p) > 4 {
+			return nil // bad group
+		}
+		k := net.IPv6len - 2*(len(parts)-i)
+		last[k], last[k+1] = byte(u>>8), byte(u)
+	}
+	return last
+}
+
+// String returns the IP range in `first-last` form.
+// Returns empty string for the zero Range.
+func (r Range) String() string {
+	if r.First == nil && r.Last == nil {
+		return ""
+	}
+	return r.First.String() + "-" + r.Last.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String().
+func (r Range) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The IP range is expected in a form accepted by ParseRange().
+// Empty text is decoded as the zero Range.
+func (r *Range) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*r = Range{}
+		return nil
+	}
+
+	out, err := ParseRange(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = out
+	return nil
+}
+
 // Summarize returns a series of networks which cover the range.
 func (r Range) Summarize() ([]*net.IPNet, error) {
 	return SummarizeRange(r.First, r.Last)
@@ -35,14 +172,11 @@ func (r Range) Summarize() ([]*net.IPNet, error) {
 // Usually the first IP address is network address,
 // while the last IP address is broadcast address.
 func RangeFromNetwork(network *net.IPNet) (first net.IP, last net.IP) {
-	if network == nil {
-		return // no network, no IP range
+	if network = canonical(network); network == nil {
+		return // no network or inconsistent IP address and IP mask
 	}
 
 	n := len(network.IP)
-	if n != len(network.Mask) {
-		return // inconsistent length: IP address and IP mask
-	}
 
 	first = make(net.IP, n)
 	last = make(net.IP, n)
@@ -53,3 +187,127 @@ func RangeFromNetwork(network *net.IPNet) (first net.IP, last net.IP) {
 
 	return
 }
+
+// NewRangeFromNetwork returns the IP range for the given network.
+// The same as RangeFromNetwork() but returns a Range value.
+func NewRangeFromNetwork(network *net.IPNet) Range {
+	return NewRange(RangeFromNetwork(network))
+}
+
+// IsEmpty returns true if the range contains no addresses.
+// Range with bad or mismatched IP addresses is considered as empty.
+func (r Range) IsEmpty() bool {
+	span, _, err := rangeSpan(r)
+	return err != nil || span.empty()
+}
+
+// Size returns the number of addresses in the range.
+// The size of entire IPv6 address space does not fit
+// into Uint128, so it is saturated to the maximum value.
+func (r Range) Size() Uint128 {
+	span, _, err := rangeSpan(r)
+	if err != nil || span.empty() {
+		return Uint128{} // empty range
+	}
+
+	d := span.last.Sub(span.first)
+	if d.Equals(u128.Max()) {
+		return d // saturated
+	}
+	return d.Add64(1)
+}
+
+// Contains returns true if the range contains the IP address.
+func (r Range) Contains(addr net.IP) bool {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return false // bad range
+	}
+
+	u, uv6, err := loadIP(addr)
+	if err != nil || v6 != uv6 {
+		return false // bad address or version mismatch
+	}
+
+	return span.first.Cmp(u) <= 0 && u.Cmp(span.last) <= 0
+}
+
+// ContainsRange returns true if the range contains all addresses of another range.
+// Empty range is contained by any range of the same IP version.
+func (r Range) ContainsRange(o Range) bool {
+	a, b, ok := rangeSpans(r, o)
+	if !ok {
+		return false // bad ranges or version mismatch
+	}
+	if b.empty() {
+		return true
+	}
+
+	return a.first.Cmp(b.first) <= 0 && b.last.Cmp(a.last) <= 0
+}
+
+// Overlaps returns true if both ranges have at least one common address.
+func (r Range) Overlaps(o Range) bool {
+	_, ok := r.Intersect(o)
+	return ok
+}
+
+// Intersect returns the range of addresses contained by both ranges.
+// Returns false if ranges do not overlap.
+func (r Range) Intersect(o Range) (Range, bool) {
+	a, b, ok := rangeSpans(r, o)
+	if !ok {
+		return Range{}, false // bad ranges or version mismatch
+	}
+
+	out := ipSpans{a}.intersect(ipSpans{b})
+	if len(out) == 0 || out[0].empty() {
+		return Range{}, false // no common addresses
+	}
+
+	return out.ranges(r.isV6())[0], true
+}
+
+// Merge returns the range of addresses contained by either range.
+// Returns false if ranges neither overlap nor are adjacent,
+// so the union cannot be represented as a single range.
+func (r Range) Merge(o Range) (Range, bool) {
+	a, b, ok := rangeSpans(r, o)
+	if !ok {
+		return Range{}, false // bad ranges or version mismatch
+	}
+
+	out := normalizeSpans([]ipSpan{a, b})
+	if len(out) != 1 {
+		return Range{}, false // disjoint or both empty
+	}
+
+	return out.ranges(r.isV6())[0], true
+}
+
+// Addresses returns all of the addresses within the range.
+func (r Range) Addresses() *IPIter {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return new(IPIter)
+	}
+	return span.addresses(v6)
+}
+
+// isV6 returns true if the range contains IPv6 addresses.
+func (r Range) isV6() bool {
+	return r.First.To4() == nil
+}
+
+// rangeSpans returns spans of both ranges of the same IP version.
+func rangeSpans(a, b Range) (as ipSpan, bs ipSpan, ok bool) {
+	as, av6, err := rangeSpan(a)
+	if err != nil {
+		return
+	}
+	bs, bv6, err := rangeSpan(b)
+	if err != nil || av6 != bv6 {
+		return
+	}
+	return as, bs, true
+}
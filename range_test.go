@@ -1,6 +1,7 @@
 package ipx_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"testing"
@@ -134,3 +135,233 @@ func TestRangeFromNetwork(tt *testing.T) {
 		assert.Equal(t, "2001:db8::8a2e:37f:ffff", last.String())
 	})
 }
+
+// ExampleRange_Merge is an example of Range.Merge
+func ExampleRange_Merge() {
+	a := ipx.NewRange(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.50"))
+	b := ipx.NewRange(net.ParseIP("10.0.0.51"), net.ParseIP("10.0.0.100"))
+	r, ok := a.Merge(b)
+	fmt.Println(r.First, r.Last, ok, r.Size())
+	// Output:
+	// 10.0.0.1 10.0.0.100 true 100
+}
+
+// ipRange is a helper function to construct IP range from strings.
+func ipRange(first, last string) ipx.Range {
+	return ipx.NewRange(net.ParseIP(first), net.ParseIP(last))
+}
+
+// TestNewRangeFromNetwork unit tests for NewRangeFromNetwork
+func TestNewRangeFromNetwork(t *testing.T) {
+	r := ipx.NewRangeFromNetwork(cidr("192.168.0.0/23"))
+	assert.Equal(t, "192.168.0.0", r.First.String())
+	assert.Equal(t, "192.168.1.255", r.Last.String())
+
+	r = ipx.NewRangeFromNetwork(nil)
+	assert.True(t, r.IsEmpty())
+}
+
+// TestRangeSize unit tests for Range.IsEmpty and Range.Size
+func TestRangeSize(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		r     ipx.Range
+		empty bool
+		size  string
+	}{
+		{"zero", ipx.Range{}, true, "0"},
+		{"mismatch", ipRange("10.0.0.1", "::1"), true, "0"},
+		{"reversed", ipRange("10.0.0.2", "10.0.0.1"), true, "0"},
+		{"single", ipRange("10.0.0.1", "10.0.0.1"), false, "1"},
+		{"ipv4_all", ipRange("0.0.0.0", "255.255.255.255"), false, "4294967296"},
+		{"ipv6_64", ipRange("2001:db8::", "2001:db8::ffff:ffff:ffff:ffff"), false, "18446744073709551616"},
+		{"ipv6_all", ipRange("::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), false, "340282366920938463463374607431768211455"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.empty, c.r.IsEmpty())
+			assert.Equal(t, c.size, c.r.Size().String())
+		})
+	}
+}
+
+// TestRangeContains unit tests for Range.Contains and Range.ContainsRange
+func TestRangeContains(t *testing.T) {
+	r := ipRange("10.0.0.10", "10.0.0.20")
+	assert.True(t, r.Contains(net.ParseIP("10.0.0.10")))
+	assert.True(t, r.Contains(net.ParseIP("10.0.0.15").To4()))
+	assert.True(t, r.Contains(net.ParseIP("10.0.0.20")))
+	assert.False(t, r.Contains(net.ParseIP("10.0.0.21")))
+	assert.False(t, r.Contains(net.ParseIP("::1")))
+	assert.False(t, r.Contains(nil))
+
+	assert.True(t, r.ContainsRange(r))
+	assert.True(t, r.ContainsRange(ipRange("10.0.0.11", "10.0.0.19")))
+	assert.True(t, r.ContainsRange(ipRange("10.0.0.2", "10.0.0.1"))) // empty
+	assert.False(t, r.ContainsRange(ipRange("10.0.0.9", "10.0.0.19")))
+	assert.False(t, r.ContainsRange(ipRange("::", "::1")))
+
+	r6 := ipRange("2001:db8::", "2001:db8::ffff")
+	assert.True(t, r6.Contains(net.ParseIP("2001:db8::1")))
+	assert.False(t, r6.Contains(net.ParseIP("10.0.0.1")))
+	assert.True(t, r6.ContainsRange(ipRange("2001:db8::10", "2001:db8::20")))
+}
+
+// TestRangeIntersect unit tests for Range.Intersect, Range.Overlaps and Range.Merge
+func TestRangeIntersect(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		a, b      ipx.Range
+		intersect string
+		merge     string
+	}{
+		{"overlapped", ipRange("10.0.0.1", "10.0.0.50"), ipRange("10.0.0.20", "10.0.0.80"), "10.0.0.20-10.0.0.50", "10.0.0.1-10.0.0.80"},
+		{"nested", ipRange("10.0.0.1", "10.0.0.50"), ipRange("10.0.0.20", "10.0.0.30"), "10.0.0.20-10.0.0.30", "10.0.0.1-10.0.0.50"},
+		{"adjacent", ipRange("10.0.0.1", "10.0.0.50"), ipRange("10.0.0.51", "10.0.0.80"), "", "10.0.0.1-10.0.0.80"},
+		{"disjoint", ipRange("10.0.0.1", "10.0.0.50"), ipRange("10.0.0.52", "10.0.0.80"), "", ""},
+		{"single", ipRange("10.0.0.1", "10.0.0.50"), ipRange("10.0.0.50", "10.0.0.50"), "10.0.0.50-10.0.0.50", "10.0.0.1-10.0.0.50"},
+		{"mismatch", ipRange("10.0.0.1", "10.0.0.50"), ipRange("::1", "::2"), "", ""},
+		{"ipv6", ipRange("::", "::ffff"), ipRange("::ff", "ffff::"), "::ff-::ffff", "::-ffff::"},
+		{"ipv6_max", ipRange("::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), ipRange("::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"),
+			"::1-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			str := func(r ipx.Range, ok bool) string {
+				if !ok {
+					return ""
+				}
+				return r.First.String() + "-" + r.Last.String()
+			}
+
+			assert.Equal(t, c.intersect, str(c.a.Intersect(c.b)))
+			assert.Equal(t, c.intersect, str(c.b.Intersect(c.a)))
+			assert.Equal(t, c.intersect != "", c.a.Overlaps(c.b))
+			assert.Equal(t, c.merge, str(c.a.Merge(c.b)))
+			assert.Equal(t, c.merge, str(c.b.Merge(c.a)))
+		})
+	}
+}
+
+// TestRangeAddresses unit tests for Range.Addresses
+func TestRangeAddresses(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		r        ipx.Range
+		expected []string
+	}{
+		{"empty", ipRange("10.0.0.2", "10.0.0.1"), nil},
+		{"bad", ipx.Range{}, nil},
+		{"ipv4", ipRange("10.0.0.254", "10.0.1.1"), []string{"10.0.0.254", "10.0.0.255", "10.0.1.0", "10.0.1.1"}},
+		{"ipv4_single", ipRange("10.0.0.1", "10.0.0.1"), []string{"10.0.0.1"}},
+		{"ipv4_max", ipRange("255.255.255.254", "255.255.255.255"), []string{"255.255.255.254", "255.255.255.255"}},
+		{"ipv6", ipRange("::fffe", "::1:0"), []string{"::fffe", "::ffff", "::1:0"}},
+		{"ipv6_max", ipRange("ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"),
+			[]string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+			for iter := c.r.Addresses(); iter.Next(); {
+				got = append(got, iter.IP().String())
+			}
+			assert.Equal(t, c.expected, got)
+		})
+	}
+}
+
+// ExampleParseRange is an example of ParseRange
+func ExampleParseRange() {
+	for _, s := range []string{
+		"10.0.0.1-10.0.0.50",
+		"10.0.0.1-50",
+		"10.0.0.0 - 10.0.0.255",
+		"10.0.0.0/30",
+		"2001:db8::1-ff",
+	} {
+		r, _ := ipx.ParseRange(s)
+		fmt.Println(r)
+	}
+	// Output:
+	// 10.0.0.1-10.0.0.50
+	// 10.0.0.1-10.0.0.50
+	// 10.0.0.0-10.0.0.255
+	// 10.0.0.0-10.0.0.3
+	// 2001:db8::1-2001:db8::ff
+}
+
+// TestParseRange unit tests for ParseRange
+func TestParseRange(tt *testing.T) {
+	tt.Run("good", func(t *testing.T) {
+		for s, expected := range map[string]string{
+			"10.0.0.1-10.0.0.50":              "10.0.0.1-10.0.0.50",
+			" 10.0.0.1 - 10.0.0.50 ":          "10.0.0.1-10.0.0.50",
+			"10.0.0.1-50":                     "10.0.0.1-10.0.0.50",
+			"10.0.0.1-1.50":                   "10.0.0.1-10.0.1.50",
+			"10.0.0.1-10.0.0.1":               "10.0.0.1-10.0.0.1",
+			"10.0.0.1":                        "10.0.0.1-10.0.0.1",
+			"192.0.2.0 - 192.0.2.255":         "192.0.2.0-192.0.2.255",
+			"192.0.2.10/24":                   "192.0.2.0-192.0.2.255",
+			"::ffff:10.0.0.1-::ffff:10.0.0.2": "10.0.0.1-10.0.0.2",
+			"2001:db8::1-2001:db8::ff":        "2001:db8::1-2001:db8::ff",
+			"2001:db8::1-ff":                  "2001:db8::1-2001:db8::ff",
+			"2001:db8::1-1:ff":                "2001:db8::1-2001:db8::1:ff",
+			"2001:db8:: - 2001:db8:0:ffff:ffff:ffff:ffff:ffff": "2001:db8::-2001:db8:0:ffff:ffff:ffff:ffff:ffff",
+			"2001:db8::/64": "2001:db8::-2001:db8::ffff:ffff:ffff:ffff",
+		} {
+			r, err := ipx.ParseRange(s)
+			if assert.NoError(t, err, s) {
+				assert.Equal(t, expected, r.String(), s)
+			}
+		}
+	})
+
+	tt.Run("bad", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"bad",
+			"10.0.0.1-",
+			"-10.0.0.1",
+			"10.0.0.1-256",
+			"10.0.0.1-0.0.0",
+			"10.0.0.50-10.0.0.1",
+			"10.0.0.50-40",
+			"10.0.0.0/33",
+			"2001:db8::1-fffff",
+			"2001:db8::1-::",
+			"2001:db8::1-1:2:3:4:5:6:7",
+		} {
+			_, err := ipx.ParseRange(s)
+			assert.ErrorIs(t, err, ipx.ErrInvalidRange, s)
+		}
+
+		_, err := ipx.ParseRange("10.0.0.1-::1")
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+	})
+}
+
+// TestRangeText unit tests for Range text encoding
+func TestRangeText(t *testing.T) {
+	type config struct {
+		Pool  ipx.Range   `json:"pool"`
+		Pools []ipx.Range `json:"pools"`
+		Empty ipx.Range   `json:"empty"`
+	}
+
+	in := config{
+		Pool:  ipRange("10.0.0.1", "10.0.0.50"),
+		Pools: []ipx.Range{ipRange("2001:db8::1", "2001:db8::ff")},
+	}
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"pool":"10.0.0.1-10.0.0.50","pools":["2001:db8::1-2001:db8::ff"],"empty":""}`, string(data))
+
+	var out config
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in.Pool.String(), out.Pool.String())
+	assert.Equal(t, in.Pools[0].String(), out.Pools[0].String())
+	assert.Equal(t, ipx.Range{}, out.Empty)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"pool":"192.0.2.0/24"}`), &out))
+	assert.Equal(t, "192.0.2.0-192.0.2.255", out.Pool.String())
+
+	err = json.Unmarshal([]byte(`{"pool":"bad"}`), &out)
+	assert.ErrorIs(t, err, ipx.ErrInvalidRange)
+}
@@ -0,0 +1,210 @@
+package ipx
+
+import (
+	"net"
+)
+
+// RangeSet is a normalized list of IP ranges.
+// Ranges are kept sorted, disjoint and merged, so
+// adjacent or overlapping ranges become a single range.
+// IPv4 ranges go first, then IPv6 ranges.
+//
+// The zero value is an empty set ready to use.
+type RangeSet struct {
+	v4 ipSpans
+	v6 ipSpans
+}
+
+// NewRangeSet returns a set containing all the IP ranges.
+// Empty ranges are ignored.
+func NewRangeSet(ranges ...Range) (*RangeSet, error) {
+	var four, six []ipSpan
+	for _, r := range ranges {
+		span, v6, err := rangeSpan(r)
+		if err != nil {
+			return nil, err
+		}
+		if v6 {
+			six = append(six, span)
+		} else {
+			four = append(four, span)
+		}
+	}
+
+	return &RangeSet{
+		v4: normalizeSpans(four),
+		v6: normalizeSpans(six),
+	}, nil
+}
+
+// NewRangeSetFromNetworks returns a set containing all the networks.
+func NewRangeSetFromNetworks(networks []*net.IPNet) (*RangeSet, error) {
+	ranges := make([]Range, 0, len(networks))
+	for _, network := range networks {
+		r := NewRangeFromNetwork(network)
+		if r.First == nil {
+			return nil, ErrInvalidNetwork
+		}
+		ranges = append(ranges, r)
+	}
+
+	return NewRangeSet(ranges...)
+}
+
+// Len returns the number of disjoint ranges in the set.
+func (s *RangeSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.v4) + len(s.v6)
+}
+
+// Add adds the IP range to the set.
+func (s *RangeSet) Add(r Range) error {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return err
+	}
+	if span.empty() {
+		return nil // nothing to add
+	}
+
+	if v6 {
+		s.v6 = s.v6.union(ipSpans{span})
+	} else {
+		s.v4 = s.v4.union(ipSpans{span})
+	}
+	return nil
+}
+
+// Remove removes the IP range from the set.
+// Ranges of the set are split if necessary.
+func (s *RangeSet) Remove(r Range) error {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return err
+	}
+	if span.empty() {
+		return nil // nothing to remove
+	}
+
+	if v6 {
+		s.v6 = s.v6.subtract(ipSpans{span})
+	} else {
+		s.v4 = s.v4.subtract(ipSpans{span})
+	}
+	return nil
+}
+
+// Contains returns true if the set contains the IP address.
+func (s *RangeSet) Contains(addr net.IP) bool {
+	_, ok := s.Lookup(addr)
+	return ok
+}
+
+// ContainsRange returns true if the set contains all addresses of the IP range.
+func (s *RangeSet) ContainsRange(r Range) bool {
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return false // bad range
+	}
+	if span.empty() {
+		return true
+	}
+
+	i := s.spans(v6).search(span.first)
+	return i >= 0 && span.last.Cmp(s.spans(v6)[i].last) <= 0
+}
+
+// Lookup returns the range of the set containing the IP address.
+// Binary search is used.
+func (s *RangeSet) Lookup(addr net.IP) (Range, bool) {
+	u, v6, err := loadIP(addr)
+	if err != nil {
+		return Range{}, false // bad address
+	}
+
+	spans := s.spans(v6)
+	i := spans.search(u)
+	if i < 0 {
+		return Range{}, false // not found
+	}
+
+	return spans[i : i+1].ranges(v6)[0], true
+}
+
+// Intersect returns a set of addresses contained by both sets.
+func (s *RangeSet) Intersect(o *RangeSet) *RangeSet {
+	return &RangeSet{
+		v4: s.spans(false).intersect(o.spans(false)),
+		v6: s.spans(true).intersect(o.spans(true)),
+	}
+}
+
+// Ranges returns all ranges of the set.
+func (s *RangeSet) Ranges() []Range {
+	return append(
+		s.spans(false).ranges(false),
+		s.spans(true).ranges(true)...)
+}
+
+// Networks returns a series of networks which cover the set.
+func (s *RangeSet) Networks() ([]*net.IPNet, error) {
+	var out []*net.IPNet
+	for _, r := range s.Ranges() {
+		nwks, err := r.Summarize()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, nwks...)
+	}
+
+	return out, nil
+}
+
+// spans returns IPv4 or IPv6 spans of the set.
+// The nil set is considered as empty.
+func (s *RangeSet) spans(v6 bool) ipSpans {
+	switch {
+	case s == nil:
+		return nil
+	case v6:
+		return s.v6
+	default:
+		return s.v4
+	}
+}
+
+// Addresses returns all of the addresses within the set.
+// Each address is visited once, in ascending order.
+func (s *RangeSet) Addresses() *RangeSetIter {
+	return &RangeSetIter{ranges: s.Ranges()}
+}
+
+// RangeSetIter permits iteration over all addresses of a RangeSet.
+type RangeSetIter struct {
+	ranges []Range
+	iter   *IPIter
+}
+
+// IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
+func (i *RangeSetIter) IP() net.IP {
+	if i.iter == nil {
+		return nil
+	}
+	return i.iter.IP()
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (i *RangeSetIter) Next() bool {
+	for {
+		if i.iter != nil && i.iter.Next() {
+			return true
+		}
+		if len(i.ranges) == 0 {
+			return false
+		}
+		i.iter = i.ranges[0].Addresses()
+		i.ranges = i.ranges[1:]
+	}
+}
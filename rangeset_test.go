@@ -0,0 +1,127 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleRangeSet is an example of RangeSet
+func ExampleRangeSet() {
+	s, _ := ipx.NewRangeSet(
+		ipRange("10.0.0.1", "10.0.0.50"),
+		ipRange("10.0.0.40", "10.0.0.100"),
+		ipRange("10.0.0.200", "10.0.0.210"),
+	)
+	_ = s.Remove(ipRange("10.0.0.60", "10.0.0.69"))
+	for _, r := range s.Ranges() {
+		fmt.Println(r.First, r.Last)
+	}
+	// Output:
+	// 10.0.0.1 10.0.0.59
+	// 10.0.0.70 10.0.0.100
+	// 10.0.0.200 10.0.0.210
+}
+
+// TestRangeSet unit tests for RangeSet
+func TestRangeSet(tt *testing.T) {
+	tt.Run("bad", func(t *testing.T) {
+		_, err := ipx.NewRangeSet(ipRange("10.0.0.1", "::1"))
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+		_, err = ipx.NewRangeSetFromNetworks([]*net.IPNet{nil})
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+
+		var s ipx.RangeSet
+		assert.ErrorIs(t, s.Add(ipx.Range{}), ipx.ErrInvalidIP)
+		assert.ErrorIs(t, s.Remove(ipx.Range{}), ipx.ErrInvalidIP)
+		assert.False(t, s.Contains(nil))
+		assert.False(t, s.ContainsRange(ipx.Range{}))
+		assert.Zero(t, s.Len())
+	})
+
+	tt.Run("add_remove", func(t *testing.T) {
+		var s ipx.RangeSet
+		require.NoError(t, s.Add(ipRange("2001:db8::10", "2001:db8::20")))
+		require.NoError(t, s.Add(ipRange("10.0.0.10", "10.0.0.20")))
+		require.NoError(t, s.Add(ipRange("10.0.0.30", "10.0.0.40")))
+		require.NoError(t, s.Add(ipRange("10.0.0.21", "10.0.0.25")))
+		require.NoError(t, s.Add(ipRange("10.0.0.9", "10.0.0.8"))) // empty
+		assert.Equal(t, []string{"10.0.0.10-10.0.0.25", "10.0.0.30-10.0.0.40", "2001:db8::10-2001:db8::20"},
+			rangeStrings(s.Ranges()))
+		assert.Equal(t, 3, s.Len())
+
+		require.NoError(t, s.Add(ipRange("10.0.0.26", "10.0.0.29")))
+		assert.Equal(t, []string{"10.0.0.10-10.0.0.40", "2001:db8::10-2001:db8::20"},
+			rangeStrings(s.Ranges()))
+
+		require.NoError(t, s.Remove(ipRange("10.0.0.15", "10.0.0.15")))
+		require.NoError(t, s.Remove(ipRange("10.0.0.35", "10.0.0.50")))
+		require.NoError(t, s.Remove(ipRange("2001:db8::", "2001:db8::ffff")))
+		assert.Equal(t, []string{"10.0.0.10-10.0.0.14", "10.0.0.16-10.0.0.34"},
+			rangeStrings(s.Ranges()))
+	})
+
+	tt.Run("lookup", func(t *testing.T) {
+		s, err := ipx.NewRangeSet(
+			ipRange("10.0.0.10", "10.0.0.20"),
+			ipRange("10.0.1.10", "10.0.1.20"),
+			ipRange("2001:db8::10", "2001:db8::20"),
+		)
+		require.NoError(t, err)
+
+		r, ok := s.Lookup(net.ParseIP("10.0.1.15"))
+		require.True(t, ok)
+		assert.Equal(t, "10.0.1.10", r.First.String())
+		assert.Equal(t, "10.0.1.20", r.Last.String())
+
+		for addr, expected := range map[string]bool{
+			"10.0.0.9":     false,
+			"10.0.0.10":    true,
+			"10.0.0.20":    true,
+			"10.0.0.21":    false,
+			"10.0.1.20":    true,
+			"10.0.1.21":    false,
+			"2001:db8::15": true,
+			"2001:db8::21": false,
+			"::a00:f":      false, // IPv4-compatible is not IPv4
+		} {
+			assert.Equal(t, expected, s.Contains(net.ParseIP(addr)), addr)
+		}
+
+		assert.True(t, s.ContainsRange(ipRange("10.0.0.11", "10.0.0.20")))
+		assert.False(t, s.ContainsRange(ipRange("10.0.0.11", "10.0.1.10")))
+		assert.True(t, s.ContainsRange(ipRange("2001:db8::10", "2001:db8::10")))
+	})
+
+	tt.Run("intersect", func(t *testing.T) {
+		a, err := ipx.NewRangeSet(ipRange("10.0.0.10", "10.0.0.20"), ipRange("10.0.0.30", "10.0.0.40"))
+		require.NoError(t, err)
+		b, err := ipx.NewRangeSet(ipRange("10.0.0.15", "10.0.0.35"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"10.0.0.15-10.0.0.20", "10.0.0.30-10.0.0.35"},
+			rangeStrings(a.Intersect(b).Ranges()))
+		assert.Zero(t, a.Intersect(nil).Len())
+	})
+
+	tt.Run("networks", func(t *testing.T) {
+		s, err := ipx.NewRangeSetFromNetworks([]*net.IPNet{
+			cidr("10.0.0.0/25"), cidr("10.0.0.128/25"), cidr("10.0.2.0/24"), cidr("2001:db8::/127"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0-10.0.0.255", "10.0.2.0-10.0.2.255", "2001:db8::-2001:db8::1"},
+			rangeStrings(s.Ranges()))
+
+		require.NoError(t, s.Remove(ipRange("10.0.0.0", "10.0.0.0")))
+		nwks, err := s.Networks()
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/29", "10.0.0.16/28",
+			"10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25", "10.0.2.0/24", "2001:db8::/127",
+		}, Networks(nwks).Strings())
+	})
+}
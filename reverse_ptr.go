@@ -2,8 +2,10 @@ package ipx
 
 import (
 	"bytes"
+	"fmt"
 	"net"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -46,3 +48,155 @@ func ReversePTR(address net.IP) string {
 
 	return "" // bad address length
 }
+
+// ParseReversePTR parses the name of the reverse DNS record, the reverse of ReversePTR.
+// Both full names, like `4.3.2.1.in-addr.arpa`, and partial names,
+// like `2.0.192.in-addr.arpa` or a partial nibble chain of `ip6.arpa`, are accepted.
+// The name is case-insensitive and may have the trailing dot.
+//
+// The network covered by the name is returned, the address is also returned
+// for the full names (i.e. /32 or /128 networks) and nil otherwise.
+func ParseReversePTR(name string) (net.IP, *net.IPNet, error) {
+	s := strings.TrimSuffix(strings.ToLower(name), ".")
+
+	var labels []string
+	var v6 bool
+	switch {
+	case s == "in-addr.arpa":
+	case s == "ip6.arpa":
+		v6 = true
+	case strings.HasSuffix(s, ".in-addr.arpa"):
+		labels = strings.Split(strings.TrimSuffix(s, ".in-addr.arpa"), ".")
+	case strings.HasSuffix(s, ".ip6.arpa"):
+		labels = strings.Split(strings.TrimSuffix(s, ".ip6.arpa"), ".")
+		v6 = true
+	default:
+		return nil, nil, fmt.Errorf("%w: %q is neither in-addr.arpa nor ip6.arpa name", ErrInvalidPTR, name)
+	}
+
+	if v6 {
+		return parseReversePTR6(name, labels)
+	}
+	return parseReversePTR4(name, labels)
+}
+
+// parseReversePTR4 parses (reversed) octet labels of in-addr.arpa name.
+func parseReversePTR4(name string, labels []string) (net.IP, *net.IPNet, error) {
+	if len(labels) > net.IPv4len {
+		return nil, nil, fmt.Errorf("%w: %q has too many labels", ErrInvalidPTR, name)
+	}
+
+	ip := make(net.IP, net.IPv4len)
+	for i, label := range labels {
+		b, err := strconv.ParseUint(label, 10, 8)
+		if err != nil || (len(label) > 1 && label[0] == '0') {
+			return nil, nil, fmt.Errorf("%w: %q has bad octet label %q", ErrInvalidPTR, name, label)
+		}
+		ip[len(labels)-1-i] = byte(b)
+	}
+
+	network := &net.IPNet{IP: ip, Mask: net.CIDRMask(8*len(labels), 8*net.IPv4len)}
+	if len(labels) == net.IPv4len {
+		return ip, network, nil
+	}
+	return nil, network, nil
+}
+
+// parseReversePTR6 parses (reversed) nibble labels of ip6.arpa name.
+func parseReversePTR6(name string, labels []string) (net.IP, *net.IPNet, error) {
+	if len(labels) > 2*net.IPv6len {
+		return nil, nil, fmt.Errorf("%w: %q has too many labels", ErrInvalidPTR, name)
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	for i, label := range labels {
+		n, err := strconv.ParseUint(label, 16, 4)
+		if err != nil || len(label) != 1 {
+			return nil, nil, fmt.Errorf("%w: %q has bad nibble label %q", ErrInvalidPTR, name, label)
+		}
+		k := len(labels) - 1 - i // nibble index
+		ip[k/2] |= byte(n) << (4 * (1 - k%2))
+	}
+
+	network := &net.IPNet{IP: ip, Mask: net.CIDRMask(4*len(labels), 8*net.IPv6len)}
+	if len(labels) == 2*net.IPv6len {
+		return ip, network, nil
+	}
+	return nil, network, nil
+}
+
+// ReverseZones returns the minimal sorted list of reverse DNS zone names covering the network.
+// Zones are cut on octet boundaries for IPv4 and on nibble boundaries for IPv6,
+// so networks not aligned to the boundaries are split, e.g.
+// `192.0.2.0/23` is covered by `2.0.192.in-addr.arpa` and `3.0.192.in-addr.arpa`.
+// Host bits of the network are ignored.
+//
+// IPv4 networks longer than /24 are covered by per-address names,
+// see ClasslessReverseZone() for delegation of such networks.
+func ReverseZones(network *net.IPNet) ([]string, error) {
+	network, err := Canonicalize(network)
+	if err != nil {
+		return nil, err
+	}
+
+	ones, bits := network.Mask.Size()
+	labelBits := 8 // octets
+	if bits == 8*net.IPv6len {
+		labelBits = 4 // nibbles
+	}
+	// round up to the label boundary
+	prefix := (ones + labelBits - 1) / labelBits * labelBits
+
+	var out []string
+	for iter := Split(network, prefix); iter.Next(); {
+		name := ReversePTR(iter.Net().IP)
+		out = append(out, trimLabels(name, (bits-prefix)/labelBits))
+	}
+	return out, nil
+}
+
+// ReverseCNAME is a CNAME record of RFC 2317 classless reverse delegation.
+type ReverseCNAME struct {
+	Name   string // e.g. `65.2.0.192.in-addr.arpa`
+	Target string // e.g. `65.64/26.2.0.192.in-addr.arpa`
+}
+
+// ClasslessReverseZone returns the child zone name for RFC 2317 classless
+// reverse delegation of the IPv4 network longer than /24, like `64/26.2.0.192.in-addr.arpa`,
+// and CNAME records for all the network addresses to be added into the parent zone.
+// Host bits of the network are ignored.
+func ClasslessReverseZone(network *net.IPNet) (string, []ReverseCNAME, error) {
+	network, err := Canonicalize(network)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ones, bits := network.Mask.Size()
+	if bits != 8*net.IPv4len {
+		return "", nil, fmt.Errorf("%w: %s is not IPv4 network", ErrVersionMismatch, network)
+	}
+	if ones <= 24 {
+		return "", nil, fmt.Errorf("%w: %s is not longer than /24", ErrInvalidNetwork, network)
+	}
+
+	parent := trimLabels(ReversePTR(network.IP), 1)
+	zone := fmt.Sprintf("%d/%d.%s", network.IP[3], ones, parent)
+
+	var cnames []ReverseCNAME
+	for iter := Addresses(network); iter.Next(); {
+		name := ReversePTR(iter.IP())
+		cnames = append(cnames, ReverseCNAME{
+			Name:   name,
+			Target: fmt.Sprintf("%s.%s", name[:strings.IndexByte(name, '.')], zone),
+		})
+	}
+	return zone, cnames, nil
+}
+
+// trimLabels removes n leading labels of the DNS name.
+func trimLabels(name string, n int) string {
+	for ; n > 0; n-- {
+		name = name[strings.IndexByte(name, '.')+1:]
+	}
+	return name
+}
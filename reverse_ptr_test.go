@@ -7,6 +7,7 @@ import (
 
 	"github.com/ns1/ipx/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ExampleReversePTR_v4 is an example of ReversePTR for IPv4.
@@ -49,3 +50,144 @@ func TestReversePTR(t *testing.T) {
 	assert.Equal(t, "", ipx.ReversePTR(net.ParseIP("bad")))
 	assert.Equal(t, "", ipx.ReversePTR(make(net.IP, 3)))
 }
+
+// ExampleParseReversePTR is an example of ParseReversePTR.
+func ExampleParseReversePTR() {
+	ip, _, _ := ipx.ParseReversePTR("10.0.168.192.in-addr.arpa.")
+	_, network, _ := ipx.ParseReversePTR("8.B.D.0.1.0.0.2.ip6.arpa")
+	fmt.Println(ip, network)
+	// Output:
+	// 192.168.0.10 2001:db8::/32
+}
+
+// TestParseReversePTR unit tests for ParseReversePTR
+func TestParseReversePTR(tt *testing.T) {
+	tt.Run("full", func(t *testing.T) {
+		for _, s := range []string{"192.168.0.10", "0.0.0.0", "2001:db8:85a3::8a2e:370:7334", "::", "::1"} {
+			ip, network, err := ipx.ParseReversePTR(ipx.ReversePTR(net.ParseIP(s)))
+			if assert.NoError(t, err, s) {
+				assert.Equal(t, s, ip.String())
+				ones, bits := network.Mask.Size()
+				assert.Equal(t, ones, bits, s)
+				assert.True(t, network.IP.Equal(ip), s)
+			}
+		}
+
+		ip, _, err := ipx.ParseReversePTR("4.3.2.1.IN-ADDR.ARPA.")
+		require.NoError(t, err)
+		assert.Equal(t, net.IP{1, 2, 3, 4}, ip)
+	})
+
+	tt.Run("partial", func(t *testing.T) {
+		for s, expected := range map[string]string{
+			"2.0.192.in-addr.arpa":          "192.0.2.0/24",
+			"10.in-addr.arpa.":              "10.0.0.0/8",
+			"in-addr.arpa":                  "0.0.0.0/0",
+			"8.b.d.0.1.0.0.2.ip6.arpa":      "2001:db8::/32",
+			"1.0.8.b.d.0.1.0.0.2.ip6.arpa.": "2001:db8:100::/40",
+			"f.ip6.arpa":                    "f000::/4",
+			"ip6.arpa.":                     "::/0",
+		} {
+			ip, network, err := ipx.ParseReversePTR(s)
+			if assert.NoError(t, err, s) {
+				assert.Nil(t, ip, s)
+				assert.Equal(t, expected, network.String(), s)
+			}
+		}
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		for s, msg := range map[string]string{
+			"example.com":               "neither in-addr.arpa nor ip6.arpa name",
+			"1.2.3.4.5.in-addr.arpa":    "too many labels",
+			"256.in-addr.arpa":          `bad octet label "256"`,
+			"01.in-addr.arpa":           `bad octet label "01"`,
+			"1..in-addr.arpa":           `bad octet label ""`,
+			"-1.in-addr.arpa":           `bad octet label "-1"`,
+			"10.ip6.arpa":               `bad nibble label "10"`,
+			"g.ip6.arpa":                `bad nibble label "g"`,
+			".ip6.arpa":                 `bad nibble label ""`,
+			"0/26.2.0.192.in-addr.arpa": `bad octet label "0/26"`,
+			"0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa": "too many labels",
+		} {
+			_, _, err := ipx.ParseReversePTR(s)
+			if assert.ErrorIs(t, err, ipx.ErrInvalidPTR, s) {
+				assert.Contains(t, err.Error(), msg, s)
+			}
+		}
+	})
+}
+
+// ExampleReverseZones is an example of ReverseZones.
+func ExampleReverseZones() {
+	zones, _ := ipx.ReverseZones(cidr("192.0.2.0/23"))
+	fmt.Println(zones)
+	// Output:
+	// [2.0.192.in-addr.arpa 3.0.192.in-addr.arpa]
+}
+
+// TestReverseZones unit tests for ReverseZones
+func TestReverseZones(t *testing.T) {
+	for s, expected := range map[string][]string{
+		"10.0.0.0/8":      {"10.in-addr.arpa"},
+		"10.1.2.3/16":     {"1.10.in-addr.arpa"},
+		"172.16.0.0/14":   {"16.172.in-addr.arpa", "17.172.in-addr.arpa", "18.172.in-addr.arpa", "19.172.in-addr.arpa"},
+		"192.0.2.252/30":  {"252.2.0.192.in-addr.arpa", "253.2.0.192.in-addr.arpa", "254.2.0.192.in-addr.arpa", "255.2.0.192.in-addr.arpa"},
+		"0.0.0.0/0":       {"in-addr.arpa"},
+		"2001:db8::/32":   {"8.b.d.0.1.0.0.2.ip6.arpa"},
+		"2001:db8::/47":   {"0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", "1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+		"2001:db8::1/128": {ipx.ReversePTR(net.ParseIP("2001:db8::1"))},
+		"::/0":            {"ip6.arpa"},
+	} {
+		zones, err := ipx.ReverseZones(cidr(s))
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, zones, s)
+		}
+	}
+
+	zones, err := ipx.ReverseZones(cidr("128.0.0.0/1"))
+	require.NoError(t, err)
+	assert.Len(t, zones, 128)
+	assert.Equal(t, "255.in-addr.arpa", zones[127])
+
+	_, err = ipx.ReverseZones(nil)
+	assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+}
+
+// TestClasslessReverseZone unit tests for ClasslessReverseZone
+func TestClasslessReverseZone(t *testing.T) {
+	zone, cnames, err := ipx.ClasslessReverseZone(cidr("192.0.2.64/26"))
+	require.NoError(t, err)
+	assert.Equal(t, "64/26.2.0.192.in-addr.arpa", zone)
+	require.Len(t, cnames, 64)
+	assert.Equal(t, ipx.ReverseCNAME{
+		Name:   "64.2.0.192.in-addr.arpa",
+		Target: "64.64/26.2.0.192.in-addr.arpa",
+	}, cnames[0])
+	assert.Equal(t, ipx.ReverseCNAME{
+		Name:   "127.2.0.192.in-addr.arpa",
+		Target: "127.64/26.2.0.192.in-addr.arpa",
+	}, cnames[63])
+
+	zone, cnames, err = ipx.ClasslessReverseZone(cidr("192.0.2.7/32"))
+	require.NoError(t, err)
+	assert.Equal(t, "7/32.2.0.192.in-addr.arpa", zone)
+	assert.Equal(t, []ipx.ReverseCNAME{{
+		Name:   "7.2.0.192.in-addr.arpa",
+		Target: "7.7/32.2.0.192.in-addr.arpa",
+	}}, cnames)
+
+	// the end of address space
+	zone, cnames, err = ipx.ClasslessReverseZone(cidr("255.255.255.128/25"))
+	require.NoError(t, err)
+	assert.Equal(t, "128/25.255.255.255.in-addr.arpa", zone)
+	require.Len(t, cnames, 128)
+	assert.Equal(t, "255.255.255.255.in-addr.arpa", cnames[127].Name)
+
+	_, _, err = ipx.ClasslessReverseZone(cidr("192.0.2.0/24"))
+	assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	_, _, err = ipx.ClasslessReverseZone(cidr("2001:db8::/120"))
+	assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+	_, _, err = ipx.ClasslessReverseZone(nil)
+	assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+}
@@ -0,0 +1,251 @@
+package ipx
+
+import (
+	"net"
+	"sort"
+
+	u128 "github.com/Pilatuz/bigx/v2/uint128"
+)
+
+// ipSpan is an inclusive [first, last] interval of IP addresses.
+// IPv4 addresses are stored in the lower 32 bits.
+type ipSpan struct {
+	first, last Uint128
+}
+
+// ipSpans is a sorted list of disjoint and non-adjacent spans.
+type ipSpans []ipSpan
+
+// netSpan returns the span of IP addresses of the network.
+func netSpan(network *net.IPNet) (span ipSpan, v6 bool, err error) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return span, false, err
+	}
+
+	if v6 {
+		span.first = key.addr
+		span.last = key.addr.Or(key.mask().Not())
+		return span, true, nil
+	}
+
+	n := key.narrow()
+	span.first = Uint128{Lo: uint64(n.addr)}
+	span.last = Uint128{Lo: uint64(n.addr | ^n.mask())}
+	return span, false, nil
+}
+
+// rangeSpan returns the span of IP addresses of the range.
+// The span is empty (first > last) if the range is empty.
+func rangeSpan(r Range) (span ipSpan, v6 bool, err error) {
+	first, firstV6, err := loadIP(r.First)
+	if err != nil {
+		return span, false, err
+	}
+	last, lastV6, err := loadIP(r.Last)
+	if err != nil {
+		return span, false, err
+	}
+	if firstV6 != lastV6 {
+		return span, false, ErrVersionMismatch
+	}
+
+	return ipSpan{first: first, last: last}, firstV6, nil
+}
+
+// loadIP reads IPv4 or IPv6 address as 128 bits integer.
+func loadIP(addr net.IP) (u Uint128, v6 bool, err error) {
+	if v4 := addr.To4(); v4 != nil {
+		return Uint128{Lo: uint64(load32(v4))}, false, nil
+	}
+	if a6 := addr.To16(); a6 != nil {
+		return load128(a6), true, nil
+	}
+	return u, false, ErrInvalidIP
+}
+
+// storeIP converts 128 bits integer back to IPv4 or IPv6 address.
+func storeIP(u Uint128, v6 bool) net.IP {
+	if v6 {
+		out := make(net.IP, net.IPv6len)
+		store128(u, out)
+		return out
+	}
+
+	out := make(net.IP, net.IPv4len)
+	store32(uint32(u.Lo), out)
+	return out
+}
+
+// empty returns true if the span contains no addresses.
+func (s ipSpan) empty() bool {
+	return s.first.Cmp(s.last) > 0
+}
+
+// addresses returns an iterator over all addresses of the span.
+func (s ipSpan) addresses(v6 bool) *IPIter {
+	if s.empty() {
+		return new(IPIter)
+	}
+
+	var iter *IPIter
+	if v6 {
+		iter = iterIPv6(s.first, Uint128{Lo: 1}, s.last)
+	} else {
+		iter = iterIPv4(uint32(s.first.Lo), 1, uint32(s.last.Lo))
+	}
+	iter.flags |= ipIterFlagInclusive
+	return iter
+}
+
+// touches returns true if the next span (which starts not before this one)
+// overlaps or is adjacent to this span.
+func (s ipSpan) touches(next ipSpan) bool {
+	if next.first.Cmp(s.last) <= 0 {
+		return true // overlapped
+	}
+	return !s.last.Equals(u128.Max()) && next.first.Equals(s.last.Add64(1))
+}
+
+// normalizeSpans sorts spans and merges overlapping and adjacent ones.
+// Empty spans are dropped. The input slice is reused.
+func normalizeSpans(spans []ipSpan) ipSpans {
+	out := spans[:0]
+	for _, s := range spans {
+		if !s.empty() {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].first.Cmp(out[j].first) < 0
+	})
+	return ipSpans(out).merged()
+}
+
+// merged merges overlapping and adjacent spans of the sorted list in place.
+func (s ipSpans) merged() ipSpans {
+	if len(s) == 0 {
+		return nil
+	}
+
+	out := s[:1]
+	for _, next := range s[1:] {
+		last := &out[len(out)-1]
+		if !last.touches(next) {
+			out = append(out, next)
+			continue
+		}
+		if next.last.Cmp(last.last) > 0 {
+			last.last = next.last
+		}
+	}
+	return out
+}
+
+// union returns spans covered by either list.
+func (s ipSpans) union(o ipSpans) ipSpans {
+	out := make(ipSpans, 0, len(s)+len(o))
+	i, j := 0, 0
+	for i < len(s) && j < len(o) {
+		if s[i].first.Cmp(o[j].first) <= 0 {
+			out = append(out, s[i])
+			i++
+		} else {
+			out = append(out, o[j])
+			j++
+		}
+	}
+	out = append(out, s[i:]...)
+	out = append(out, o[j:]...)
+	return out.merged()
+}
+
+// intersect returns spans covered by both lists.
+func (s ipSpans) intersect(o ipSpans) ipSpans {
+	var out ipSpans
+	i, j := 0, 0
+	for i < len(s) && j < len(o) {
+		first, last := s[i].first, s[i].last
+		if o[j].first.Cmp(first) > 0 {
+			first = o[j].first
+		}
+		if o[j].last.Cmp(last) < 0 {
+			last = o[j].last
+		}
+		if first.Cmp(last) <= 0 {
+			out = append(out, ipSpan{first: first, last: last})
+		}
+
+		// advance the span which ends first
+		if s[i].last.Cmp(o[j].last) < 0 {
+			i++
+		} else {
+			j++
+		}
+	}
+	return out
+}
+
+// subtract returns spans covered by this list but not by the other one.
+func (s ipSpans) subtract(o ipSpans) ipSpans {
+	var out ipSpans
+	j := 0
+	for _, a := range s {
+		// skip holes before the span
+		for j < len(o) && o[j].last.Cmp(a.first) < 0 {
+			j++
+		}
+
+		first, cut := a.first, false
+		for k := j; k < len(o) && o[k].first.Cmp(a.last) <= 0; k++ {
+			if o[k].first.Cmp(first) > 0 {
+				out = append(out, ipSpan{first: first, last: o[k].first.Sub64(1)})
+			}
+			if o[k].last.Cmp(a.last) >= 0 {
+				cut = true // the rest of span is covered
+				break
+			}
+			first = o[k].last.Add64(1)
+			j = k + 1
+		}
+		if !cut {
+			out = append(out, ipSpan{first: first, last: a.last})
+		}
+	}
+	return out
+}
+
+// search returns the index of the span containing the address or -1.
+func (s ipSpans) search(u Uint128) int {
+	i := sort.Search(len(s), func(i int) bool {
+		return s[i].last.Cmp(u) >= 0
+	})
+	if i < len(s) && s[i].first.Cmp(u) <= 0 {
+		return i
+	}
+	return -1
+}
+
+// networks returns the minimal list of networks covering all spans.
+func (s ipSpans) networks(v6 bool) []*net.IPNet {
+	var out []*net.IPNet
+	for _, span := range s {
+		if v6 {
+			out = append(out, summarizeRange6(span.first, span.last).asNets()...)
+		} else {
+			out = append(out, summarizeRange4(uint32(span.first.Lo), uint32(span.last.Lo)).asNets()...)
+		}
+	}
+	return out
+}
+
+// ranges returns all spans as IP ranges.
+func (s ipSpans) ranges(v6 bool) []Range {
+	var out []Range
+	for _, span := range s {
+		out = append(out, NewRange(
+			storeIP(span.first, v6),
+			storeIP(span.last, v6)))
+	}
+	return out
+}
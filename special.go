@@ -0,0 +1,309 @@
+package ipx
+
+import (
+	"net"
+	"sort"
+)
+
+// SpecialPurpose is an entry of the IANA IPv4 or IPv6
+// Special-Purpose Address Registry, see RFC 6890.
+//
+// Attributes marked as "N/A" in the registry are false.
+type SpecialPurpose struct {
+	Network            *net.IPNet
+	Name               string
+	RFC                string
+	Source             bool // valid as source address
+	Destination        bool // valid as destination address
+	Forwardable        bool // can be forwarded by routers
+	GloballyReachable  bool // reachable beyond the local domain
+	ReservedByProtocol bool // reserved by the protocol specification
+
+	class specialClass
+}
+
+// specialClass is a set of address classes the entry belongs to.
+type specialClass uint8
+
+const (
+	specialShared specialClass = 1 << iota
+	specialLoopback
+	specialLinkLocal
+	specialDocumentation
+	specialBenchmarking
+)
+
+// specialRegistry contains the entries of both IANA registries as of 2025.
+var specialRegistry = []SpecialPurpose{
+	// IPv4
+	{Network: mustCIDR("0.0.0.0/8"), Name: "This network", RFC: "RFC 791", Source: true, ReservedByProtocol: true},
+	{Network: mustCIDR("0.0.0.0/32"), Name: "This host on this network", RFC: "RFC 1122", Source: true, ReservedByProtocol: true},
+	{Network: mustCIDR("10.0.0.0/8"), Name: "Private-Use", RFC: "RFC 1918", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("100.64.0.0/10"), Name: "Shared Address Space", RFC: "RFC 6598", Source: true, Destination: true, Forwardable: true, class: specialShared},
+	{Network: mustCIDR("127.0.0.0/8"), Name: "Loopback", RFC: "RFC 1122", ReservedByProtocol: true, class: specialLoopback},
+	{Network: mustCIDR("169.254.0.0/16"), Name: "Link Local", RFC: "RFC 3927", Source: true, Destination: true, ReservedByProtocol: true, class: specialLinkLocal},
+	{Network: mustCIDR("172.16.0.0/12"), Name: "Private-Use", RFC: "RFC 1918", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("192.0.0.0/24"), Name: "IETF Protocol Assignments", RFC: "RFC 6890"},
+	{Network: mustCIDR("192.0.0.0/29"), Name: "IPv4 Service Continuity Prefix", RFC: "RFC 7335", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("192.0.0.8/32"), Name: "IPv4 dummy address", RFC: "RFC 7600", Source: true},
+	{Network: mustCIDR("192.0.0.9/32"), Name: "Port Control Protocol Anycast", RFC: "RFC 7723", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("192.0.0.10/32"), Name: "Traversal Using Relays around NAT Anycast", RFC: "RFC 8155", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("192.0.0.170/32"), Name: "NAT64/DNS64 Discovery", RFC: "RFC 8880", ReservedByProtocol: true},
+	{Network: mustCIDR("192.0.0.171/32"), Name: "NAT64/DNS64 Discovery", RFC: "RFC 8880", ReservedByProtocol: true},
+	{Network: mustCIDR("192.0.2.0/24"), Name: "Documentation (TEST-NET-1)", RFC: "RFC 5737", class: specialDocumentation},
+	{Network: mustCIDR("192.31.196.0/24"), Name: "AS112-v4", RFC: "RFC 7535", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("192.52.193.0/24"), Name: "AMT", RFC: "RFC 7450", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("192.168.0.0/16"), Name: "Private-Use", RFC: "RFC 1918", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("192.175.48.0/24"), Name: "Direct Delegation AS112 Service", RFC: "RFC 7534", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("198.18.0.0/15"), Name: "Benchmarking", RFC: "RFC 2544", Source: true, Destination: true, Forwardable: true, class: specialBenchmarking},
+	{Network: mustCIDR("198.51.100.0/24"), Name: "Documentation (TEST-NET-2)", RFC: "RFC 5737", class: specialDocumentation},
+	{Network: mustCIDR("203.0.113.0/24"), Name: "Documentation (TEST-NET-3)", RFC: "RFC 5737", class: specialDocumentation},
+	{Network: mustCIDR("240.0.0.0/4"), Name: "Reserved", RFC: "RFC 1112", ReservedByProtocol: true},
+	{Network: mustCIDR("255.255.255.255/32"), Name: "Limited Broadcast", RFC: "RFC 919", Destination: true, ReservedByProtocol: true},
+
+	// IPv6
+	{Network: mustCIDR("::1/128"), Name: "Loopback Address", RFC: "RFC 4291", ReservedByProtocol: true, class: specialLoopback},
+	{Network: mustCIDR("::/128"), Name: "Unspecified Address", RFC: "RFC 4291", Source: true, ReservedByProtocol: true},
+	{Network: mustCIDR("::ffff:0:0/96"), Name: "IPv4-mapped Address", RFC: "RFC 4291", ReservedByProtocol: true},
+	{Network: mustCIDR("64:ff9b::/96"), Name: "IPv4-IPv6 Translat.", RFC: "RFC 6052", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("64:ff9b:1::/48"), Name: "IPv4-IPv6 Translat.", RFC: "RFC 8215", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("100::/64"), Name: "Discard-Only Address Block", RFC: "RFC 6666", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("100:0:0:1::/64"), Name: "Dummy IPv6 Prefix", RFC: "RFC 9780", Source: true},
+	{Network: mustCIDR("2001::/23"), Name: "IETF Protocol Assignments", RFC: "RFC 2928"},
+	{Network: mustCIDR("2001::/32"), Name: "TEREDO", RFC: "RFC 4380", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("2001:1::1/128"), Name: "Port Control Protocol Anycast", RFC: "RFC 7723", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:1::2/128"), Name: "Traversal Using Relays around NAT Anycast", RFC: "RFC 8155", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:1::3/128"), Name: "DNS-SD Service Registration Protocol Anycast", RFC: "RFC 9665", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:2::/48"), Name: "Benchmarking", RFC: "RFC 5180", Source: true, Destination: true, Forwardable: true, class: specialBenchmarking},
+	{Network: mustCIDR("2001:3::/32"), Name: "AMT", RFC: "RFC 7450", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:4:112::/48"), Name: "AS112-v6", RFC: "RFC 7535", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:20::/28"), Name: "ORCHIDv2", RFC: "RFC 7343", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:30::/28"), Name: "Drone Remote ID Protocol Entity Tags (DETs) Prefix", RFC: "RFC 9374", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("2001:db8::/32"), Name: "Documentation", RFC: "RFC 3849", class: specialDocumentation},
+	{Network: mustCIDR("2002::/16"), Name: "6to4", RFC: "RFC 3056", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("2620:4f:8000::/48"), Name: "Direct Delegation AS112 Service", RFC: "RFC 7534", Source: true, Destination: true, Forwardable: true, GloballyReachable: true},
+	{Network: mustCIDR("3fff::/20"), Name: "Documentation", RFC: "RFC 9637", class: specialDocumentation},
+	{Network: mustCIDR("5f00::/16"), Name: "Segment Routing (SRv6) SIDs", RFC: "RFC 9602", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("fc00::/7"), Name: "Unique-Local", RFC: "RFC 4193", Source: true, Destination: true, Forwardable: true},
+	{Network: mustCIDR("fe80::/10"), Name: "Link-Local Unicast", RFC: "RFC 4291", Source: true, Destination: true, ReservedByProtocol: true, class: specialLinkLocal},
+}
+
+// specialMulticast contains IPv4 and IPv6 multicast networks.
+// They are not in the registries, so only used to classify networks.
+var specialMulticast = []*net.IPNet{
+	mustCIDR("224.0.0.0/4"),
+	mustCIDR("ff00::/8"),
+}
+
+var (
+	// specialTable maps registry networks to their indexes.
+	specialTable = newSpecialTable()
+
+	// specialNonGlobal contains all addresses which are not globally reachable.
+	specialNonGlobal = newSpecialNonGlobal()
+
+	// specialPrivate contains all addresses which are not globally reachable
+	// except the shared address space, the same way as Python's ipaddress does.
+	specialPrivate = specialNonGlobal.Difference(newSpecialSet(specialShared))
+)
+
+// SpecialPurposes returns all entries of the IANA Special-Purpose Address Registries.
+// IPv4 entries go first. The networks are shared and must not be modified.
+func SpecialPurposes() []SpecialPurpose {
+	return append([]SpecialPurpose(nil), specialRegistry...)
+}
+
+// LookupSpecialPurpose returns the most specific registry entry containing the IP address.
+// IPv4-mapped IPv6 addresses are looked up as IPv4 addresses.
+func LookupSpecialPurpose(addr net.IP) (SpecialPurpose, bool) {
+	_, i, ok := specialTable.LookupLPM(addr)
+	if !ok {
+		return SpecialPurpose{}, false
+	}
+	return specialRegistry[i], true
+}
+
+// LookupSpecialPurposeNet returns the most specific registry entry containing the entire network.
+func LookupSpecialPurposeNet(network *net.IPNet) (SpecialPurpose, bool) {
+	if network == nil {
+		return SpecialPurpose{}, false
+	}
+
+	found := specialTable.LookupAll(network.IP)
+	for k := len(found) - 1; k >= 0; k-- {
+		if IsSubnet(found[k].Network, network) {
+			return specialRegistry[found[k].Value], true
+		}
+	}
+	return SpecialPurpose{}, false
+}
+
+// IsGlobal returns true if the IP address is globally reachable
+// according to the most specific registry entry.
+// Addresses not in the registry are considered as globally reachable.
+func IsGlobal(addr net.IP) bool {
+	return addr.To16() != nil && !specialNonGlobal.Contains(addr)
+}
+
+// IsGlobalNet returns true if all addresses of the network are globally reachable.
+func IsGlobalNet(network *net.IPNet) bool {
+	span, v6, err := netSpan(network)
+	return err == nil && len(ipSpans{span}.intersect(specialNonGlobal.spans(v6))) == 0
+}
+
+// IsPrivate returns true if the IP address is not globally reachable.
+// Unlike net.IP.IsPrivate() it follows the registry, so loopback, link-local,
+// documentation and other special-purpose addresses are private too.
+// The shared address space `100.64.0.0/10` is neither private nor global.
+func IsPrivate(addr net.IP) bool {
+	return specialPrivate.Contains(addr)
+}
+
+// IsPrivateNet returns true if all addresses of the network are private.
+func IsPrivateNet(network *net.IPNet) bool {
+	return containsNet(specialPrivate, network)
+}
+
+// IsShared returns true if the IP address belongs to
+// the shared address space `100.64.0.0/10` used by carrier-grade NAT.
+func IsShared(addr net.IP) bool {
+	return specialClassOf(addr)&specialShared != 0
+}
+
+// IsSharedNet returns true if the network is within the shared address space.
+func IsSharedNet(network *net.IPNet) bool {
+	return specialClassOfNet(network)&specialShared != 0
+}
+
+// IsDocumentation returns true if the IP address is reserved for documentation.
+func IsDocumentation(addr net.IP) bool {
+	return specialClassOf(addr)&specialDocumentation != 0
+}
+
+// IsDocumentationNet returns true if the network is reserved for documentation.
+func IsDocumentationNet(network *net.IPNet) bool {
+	return specialClassOfNet(network)&specialDocumentation != 0
+}
+
+// IsBenchmarking returns true if the IP address is reserved for benchmarking.
+func IsBenchmarking(addr net.IP) bool {
+	return specialClassOf(addr)&specialBenchmarking != 0
+}
+
+// IsBenchmarkingNet returns true if the network is reserved for benchmarking.
+func IsBenchmarkingNet(network *net.IPNet) bool {
+	return specialClassOfNet(network)&specialBenchmarking != 0
+}
+
+// IsLoopbackNet returns true if the network is within the loopback addresses.
+// Use net.IP.IsLoopback() for a single address.
+func IsLoopbackNet(network *net.IPNet) bool {
+	return specialClassOfNet(network)&specialLoopback != 0
+}
+
+// IsLinkLocalNet returns true if the network is within the link-local unicast addresses.
+// Use net.IP.IsLinkLocalUnicast() for a single address.
+func IsLinkLocalNet(network *net.IPNet) bool {
+	return specialClassOfNet(network)&specialLinkLocal != 0
+}
+
+// IsMulticastNet returns true if the network is within the multicast addresses.
+// Use net.IP.IsMulticast() for a single address.
+func IsMulticastNet(network *net.IPNet) bool {
+	for _, m := range specialMulticast {
+		if IsSubnet(m, network) {
+			return true
+		}
+	}
+	return false
+}
+
+// specialClassOf returns classes of the most specific entry containing the IP address.
+func specialClassOf(addr net.IP) specialClass {
+	s, _ := LookupSpecialPurpose(addr)
+	return s.class
+}
+
+// specialClassOfNet returns classes of the most specific entry containing the network.
+func specialClassOfNet(network *net.IPNet) specialClass {
+	s, _ := LookupSpecialPurposeNet(network)
+	return s.class
+}
+
+// containsNet returns true if the set contains all addresses of the network.
+func containsNet(s *IPSet, network *net.IPNet) bool {
+	span, v6, err := netSpan(network)
+	return err == nil && len(ipSpans{span}.subtract(s.spans(v6))) == 0
+}
+
+// newSpecialTable builds the registry lookup table.
+func newSpecialTable() *Table[int] {
+	t := new(Table[int])
+	for i, s := range specialRegistry {
+		if isMappedNet(s.Network) {
+			continue
+		}
+		if err := t.Insert(s.Network, i); err != nil {
+			panic(err)
+		}
+	}
+	return t
+}
+
+// newSpecialNonGlobal builds the set of not globally reachable addresses.
+// More specific entries override less specific ones.
+func newSpecialNonGlobal() *IPSet {
+	entries := SpecialPurposes()
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, _ := entries[i].Network.Mask.Size()
+		b, _ := entries[j].Network.Mask.Size()
+		return a < b
+	})
+
+	s := new(IPSet)
+	for _, e := range entries {
+		if isMappedNet(e.Network) {
+			continue
+		}
+
+		var err error
+		if e.GloballyReachable {
+			err = s.RemoveNet(e.Network)
+		} else {
+			err = s.AddNet(e.Network)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+	return s
+}
+
+// newSpecialSet builds the set of addresses of all entries of the class.
+func newSpecialSet(class specialClass) *IPSet {
+	s := new(IPSet)
+	for _, e := range specialRegistry {
+		if e.class&class != 0 {
+			if err := s.AddNet(e.Network); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return s
+}
+
+// isMappedNet returns true for the IPv4-mapped IPv6 network.
+// Such networks are treated as IPv4, so the registry entry
+// `::ffff:0:0/96` would cover the entire IPv4 address space.
+func isMappedNet(network *net.IPNet) bool {
+	return len(network.Mask) == net.IPv6len && network.IP.To4() != nil
+}
+
+// mustCIDR parses the network in CIDR notation or panics.
+func mustCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
@@ -0,0 +1,129 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleLookupSpecialPurpose is an example of special-purpose address lookup
+func ExampleLookupSpecialPurpose() {
+	s, _ := ipx.LookupSpecialPurpose(net.ParseIP("192.0.0.9"))
+	fmt.Println(s.Network, s.Name, s.GloballyReachable)
+	fmt.Println(ipx.IsPrivate(net.ParseIP("10.1.2.3")), ipx.IsGlobal(net.ParseIP("8.8.8.8")))
+	// Output:
+	// 192.0.0.9/32 Port Control Protocol Anycast true
+	// true true
+}
+
+// TestSpecialPurpose unit tests for special-purpose address classification
+func TestSpecialPurpose(tt *testing.T) {
+	tt.Run("lookup", func(t *testing.T) {
+		for _, c := range []struct {
+			addr     string
+			expected string
+		}{
+			{"10.1.2.3", "10.0.0.0/8"},
+			{"::ffff:10.1.2.3", "10.0.0.0/8"},
+			{"192.0.0.1", "192.0.0.0/29"},
+			{"192.0.0.100", "192.0.0.0/24"},
+			{"0.0.0.0", "0.0.0.0/32"},
+			{"2001:1::1", "2001:1::1/128"},
+			{"2001:0:1::", "2001::/32"},
+			{"fe80::1", "fe80::/10"},
+			{"8.8.8.8", ""},
+			{"2606:4700::1111", ""},
+		} {
+			s, ok := ipx.LookupSpecialPurpose(net.ParseIP(c.addr))
+			if c.expected == "" {
+				assert.False(t, ok, c.addr)
+				continue
+			}
+			require.True(t, ok, c.addr)
+			assert.Equal(t, c.expected, s.Network.String(), c.addr)
+		}
+
+		s, ok := ipx.LookupSpecialPurposeNet(cidr("192.0.0.0/30"))
+		require.True(t, ok)
+		assert.Equal(t, "IPv4 Service Continuity Prefix", s.Name)
+		s, ok = ipx.LookupSpecialPurposeNet(cidr("192.0.0.0/28"))
+		require.True(t, ok)
+		assert.Equal(t, "IETF Protocol Assignments", s.Name)
+		_, ok = ipx.LookupSpecialPurposeNet(cidr("10.0.0.0/7"))
+		assert.False(t, ok)
+		_, ok = ipx.LookupSpecialPurposeNet(nil)
+		assert.False(t, ok)
+
+		assert.Len(t, ipx.SpecialPurposes(), 48)
+	})
+
+	tt.Run("attributes", func(t *testing.T) {
+		s, ok := ipx.LookupSpecialPurpose(net.ParseIP("169.254.1.1"))
+		require.True(t, ok)
+		assert.Equal(t, "RFC 3927", s.RFC)
+		assert.True(t, s.Source)
+		assert.True(t, s.Destination)
+		assert.False(t, s.Forwardable)
+		assert.False(t, s.GloballyReachable)
+		assert.True(t, s.ReservedByProtocol)
+	})
+
+	tt.Run("addresses", func(t *testing.T) {
+		for _, c := range []struct {
+			addr                                        string
+			global, private, shared, docs, benchmarking bool
+		}{
+			{"8.8.8.8", true, false, false, false, false},
+			{"10.0.0.1", false, true, false, false, false},
+			{"100.64.0.1", false, false, true, false, false},
+			{"127.0.0.1", false, true, false, false, false},
+			{"192.0.0.9", true, false, false, false, false},
+			{"192.0.2.1", false, true, false, true, false},
+			{"198.19.0.1", false, true, false, false, true},
+			{"224.0.0.1", true, false, false, false, false},
+			{"255.255.255.255", false, true, false, false, false},
+			{"2001:db8::1", false, true, false, true, false},
+			{"3fff::1", false, true, false, true, false},
+			{"2001:2::1", false, true, false, false, true},
+			{"2001:4860::8888", true, false, false, false, false},
+			{"fd00::1", false, true, false, false, false},
+		} {
+			addr := net.ParseIP(c.addr)
+			assert.Equal(t, c.global, ipx.IsGlobal(addr), c.addr)
+			assert.Equal(t, c.private, ipx.IsPrivate(addr), c.addr)
+			assert.Equal(t, c.shared, ipx.IsShared(addr), c.addr)
+			assert.Equal(t, c.docs, ipx.IsDocumentation(addr), c.addr)
+			assert.Equal(t, c.benchmarking, ipx.IsBenchmarking(addr), c.addr)
+		}
+
+		assert.False(t, ipx.IsGlobal(nil))
+		assert.False(t, ipx.IsPrivate(nil))
+	})
+
+	tt.Run("networks", func(t *testing.T) {
+		assert.True(t, ipx.IsGlobalNet(cidr("8.0.0.0/8")))
+		assert.False(t, ipx.IsGlobalNet(cidr("0.0.0.0/0")))
+		assert.True(t, ipx.IsGlobalNet(cidr("2001:1::1/128")))
+		assert.False(t, ipx.IsGlobalNet(cidr("2001:1::/126")))
+		assert.False(t, ipx.IsGlobalNet(nil))
+
+		assert.True(t, ipx.IsPrivateNet(cidr("10.1.0.0/16")))
+		assert.False(t, ipx.IsPrivateNet(cidr("192.0.0.0/24")))
+		assert.True(t, ipx.IsPrivateNet(cidr("192.0.0.0/29")))
+		assert.False(t, ipx.IsPrivateNet(cidr("100.64.0.0/16")))
+
+		assert.True(t, ipx.IsSharedNet(cidr("100.64.0.0/16")))
+		assert.True(t, ipx.IsDocumentationNet(cidr("2001:db8:1::/48")))
+		assert.False(t, ipx.IsDocumentationNet(cidr("2001:d00::/24")))
+		assert.True(t, ipx.IsBenchmarkingNet(cidr("198.18.0.0/15")))
+		assert.True(t, ipx.IsLoopbackNet(cidr("127.0.0.0/16")))
+		assert.True(t, ipx.IsLinkLocalNet(cidr("fe80::/64")))
+		assert.True(t, ipx.IsMulticastNet(cidr("239.0.0.0/8")))
+		assert.True(t, ipx.IsMulticastNet(cidr("ff02::/16")))
+		assert.False(t, ipx.IsMulticastNet(cidr("0.0.0.0/0")))
+	})
+}
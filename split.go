@@ -5,66 +5,126 @@ import (
 )
 
 // Split splits a subnet into smaller subnets according to the new prefix provided.
+// Host bits of the subnet are ignored.
 func Split(ipNet *net.IPNet, newPrefix int) *NetIter {
-	ones, bits := ipNet.Mask.Size()
+	network, err := Canonicalize(ipNet)
+	if err != nil {
+		return new(NetIter)
+	}
+	if len(network.Mask) < len(ipNet.Mask) {
+		newPrefix -= 96 // IPv4-mapped IPv6 network
+	}
+
+	ones, bits := network.Mask.Size()
 	if ones > newPrefix || newPrefix > bits {
 		return new(NetIter)
 	}
-	if ipNet.IP.To4() != nil {
-		ip := load32(ipNet.IP)
-		return &NetIter{
-			ips: *iterIPv4(ip, 1<<(bits-newPrefix), ip|(1<<(bits-ones)-1)),
-			net: &net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
-		}
+	if network.IP.To4() != nil {
+		return split4(load32(network.IP), ones, bits, newPrefix)
 	}
+	return split6(load128(network.IP), ones, bits, newPrefix)
+}
 
-	ip := load128(ipNet.IP)
+func split4(ip uint32, ones, bits, newPrefix int) *NetIter {
+	// the limit is the last subnet included, so there is no wrap around
+	// at the end of address space
+	incr := uint32(1) << (bits - newPrefix)
+	last := (ip | (1<<(bits-ones) - 1)) &^ (incr - 1)
+	if incr == 0 {
+		incr = 1 // the only subnet of entire address space
+	}
 
-	incr := Uint128{Lo: 1}.Lsh(uint(bits - newPrefix))
+	iter := iterIPv4(ip, incr, last)
+	iter.flags |= ipIterFlagInclusive
+	return &NetIter{
+		ips: *iter,
+		net: &net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
+	}
+}
 
-	broadCast := Uint128{Lo: 1}.
+func split6(ip Uint128, ones, bits, newPrefix int) *NetIter {
+	// the limit is the last subnet included, so there is no wrap around
+	// at the end of address space
+	incr := Uint128{Lo: 1}.Lsh(uint(bits - newPrefix))
+	last := Uint128{Lo: 1}.
 		Lsh(uint(bits - ones)).
 		Sub64(1).
-		Or(ip)
+		Or(ip).
+		AndNot(incr.Sub64(1))
+	if incr.IsZero() {
+		incr = Uint128{Lo: 1} // the only subnet of entire address space
+	}
 
+	iter := iterIPv6(ip, incr, last)
+	iter.flags |= ipIterFlagInclusive
 	return &NetIter{
-		*iterIPv6(ip, incr, broadCast),
-		&net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
+		ips: *iter,
+		net: &net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
 	}
 }
 
 // Addresses returns all of the addresses within a network.
+// Host bits of the network are ignored.
 func Addresses(ipNet *net.IPNet) *IPIter {
-	ones, bits := ipNet.Mask.Size()
-	if ipNet.IP.To4() != nil {
-		ip := load32(ipNet.IP)
-		return iterIPv4(
-			ip,
-			1,
-			ip+(1<<(bits-ones)),
-		)
+	network, err := Canonicalize(ipNet)
+	if err != nil {
+		return new(IPIter)
 	}
-	ip := load128(ipNet.IP)
-	return iterIPv6(
-		ip,
-		Uint128{Lo: 1},
-		ip.Add(Uint128{Lo: 1}.Lsh(uint(bits-ones))),
-	)
+
+	ones, bits := network.Mask.Size()
+	if network.IP.To4() != nil {
+		return addresses4(load32(network.IP), ones, bits)
+	}
+	return addresses6(load128(network.IP), ones, bits)
 }
 
-// Hosts returns all of the usable addresses within a network except the network itself address and the broadcast address
+func addresses4(ip uint32, ones, bits int) *IPIter {
+	// the limit is the last address included, so there is no wrap around
+	// at the end of address space
+	iter := iterIPv4(ip, 1, ip|(1<<(bits-ones)-1))
+	iter.flags |= ipIterFlagInclusive
+	return iter
+}
+
+func addresses6(ip Uint128, ones, bits int) *IPIter {
+	// the limit is the last address included, so there is no wrap around
+	// at the end of address space
+	last := Uint128{Lo: 1}.
+		Lsh(uint(bits - ones)).
+		Sub64(1).
+		Or(ip)
+
+	iter := iterIPv6(ip, Uint128{Lo: 1}, last)
+	iter.flags |= ipIterFlagInclusive
+	return iter
+}
+
+// Hosts returns all of the usable addresses within a network except the network itself address and the broadcast address.
+// Host bits of the network are ignored.
 func Hosts(ipNet *net.IPNet) *IPIter {
-	ones, bits := ipNet.Mask.Size()
-	if ipNet.IP.To4() != nil {
-		ip := load32(ipNet.IP) + 1
-		return iterIPv4(
-			ip,
-			1,
-			ip+(1<<(bits-ones))-2,
-		)
+	network, err := Canonicalize(ipNet)
+	if err != nil {
+		return new(IPIter)
+	}
+
+	ones, bits := network.Mask.Size()
+	if network.IP.To4() != nil {
+		return hosts4(load32(network.IP), ones, bits)
 	}
+	return hosts6(load128(network.IP), ones, bits)
+}
+
+func hosts4(ip uint32, ones, bits int) *IPIter {
+	ip++
+	return iterIPv4(
+		ip,
+		1,
+		ip+(1<<(bits-ones))-2,
+	)
+}
 
-	ip := load128(ipNet.IP).Add64(1)
+func hosts6(ip Uint128, ones, bits int) *IPIter {
+	ip = ip.Add64(1)
 
 	addend := Uint128{Lo: 1}.
 		Lsh(uint(bits - ones)).
@@ -37,6 +37,30 @@ func TestSplit(t *testing.T) {
 			26,
 			[]string{"::/26", "0:40::/26", "0:80::/26", "0:c0::/26"},
 		},
+		{
+			"ipv4 end of address space",
+			"255.255.255.0/24",
+			25,
+			[]string{"255.255.255.0/25", "255.255.255.128/25"},
+		},
+		{
+			"ipv6 end of address space",
+			"ffff::/16",
+			17,
+			[]string{"ffff::/17", "ffff:8000::/17"},
+		},
+		{
+			"ipv4 entire address space",
+			"0.0.0.0/0",
+			0,
+			[]string{"0.0.0.0/0"},
+		},
+		{
+			"ipv6 entire address space",
+			"::/0",
+			1,
+			[]string{"::/1", "8000::/1"},
+		},
 	} {
 		t.Run(c.name, func(t *testing.T) {
 			var nets []string
@@ -71,7 +95,7 @@ func ExampleSplit() {
 	// 10.0.0.192/26
 }
 
-func ExampleSplit_IP6() {
+func ExampleSplit_ip6() {
 	c := cidr("::/24")
 	split := ipx.Split(c, 26)
 	for split.Next() {
@@ -156,6 +180,13 @@ func TestAddresses(t *testing.T) {
 			},
 		},
 		{"ipv6 128", "1bc1:6d67:4ec8::3/128", []string{"1bc1:6d67:4ec8::3"}},
+		{"ipv4 end of address space", "255.255.255.252/30", []string{"255.255.255.252", "255.255.255.253", "255.255.255.254", "255.255.255.255"}},
+		{"ipv4 last address", "255.255.255.255/32", []string{"255.255.255.255"}},
+		{
+			"ipv6 end of address space",
+			"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127",
+			[]string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		},
 	} {
 		t.Run(c.name, func(t *testing.T) {
 			_, ipN, _ := net.ParseCIDR(c.net)
@@ -230,7 +261,7 @@ func ExampleHosts() {
 	// 10.0.0.6
 }
 
-func ExampleHosts_IP6() {
+func ExampleHosts_ip6() {
 	c := cidr("::/125")
 	hosts := ipx.Hosts(c)
 	for hosts.Next() {
@@ -0,0 +1,326 @@
+package ipx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+)
+
+// SubnetAllocator allocates subnets of parent networks (pools)
+// using buddy allocation: free blocks are split in halves on
+// allocation and merged back with their buddies on release,
+// so the free space is kept as less fragmented as possible.
+//
+// IPv4 and IPv6 pools can be mixed, Allocate() uses the pools of the requested
+// family only. The zero value has no pools.
+// SubnetAllocator is safe for concurrent use.
+//
+// SubnetAllocator is encoded as JSON containing pools and allocated
+// subnets, so the state can be saved and restored later.
+type SubnetAllocator struct {
+	mu    sync.Mutex
+	pools []*subnetPool
+}
+
+// subnetPool is a parent network with its free and allocated blocks.
+// All the blocks are kept as keys made by tableKey().
+type subnetPool struct {
+	root ip6Net
+	v6   bool
+	free map[ip6Net]struct{}
+	used map[ip6Net]struct{}
+}
+
+// subnetAllocatorJSON is the JSON representation of SubnetAllocator.
+type subnetAllocatorJSON struct {
+	Pools     []string `json:"pools"`
+	Allocated []string `json:"allocated"`
+}
+
+// NewSubnetAllocator returns an allocator of the pools.
+// Host bits of the pools are ignored. Pools should not overlap.
+func NewSubnetAllocator(pools ...*net.IPNet) (*SubnetAllocator, error) {
+	a := new(SubnetAllocator)
+	for _, pool := range pools {
+		if err := a.addPool(pool); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Allocate returns a free IPv4 or IPv6 subnet of the given prefix length.
+// Pools of the family are checked in order, the first one having a free block
+// of suitable size is used. ErrExhausted is returned if there is no such pool,
+// pools of the other family are never used.
+func (a *SubnetAllocator) Allocate(prefixLen int, v6 bool) (*net.IPNet, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, p := range a.pools {
+		if p.v6 != v6 {
+			continue
+		}
+		if key, ok := p.allocate(prefixLen); ok {
+			return keyNet(key, p.v6), nil
+		}
+	}
+
+	family := "IPv4"
+	if v6 {
+		family = "IPv6"
+	}
+	return nil, fmt.Errorf("%w: no free %s /%d", ErrExhausted, family, prefixLen)
+}
+
+// AllocateSpecific allocates exactly the given subnet.
+// ErrAllocated is returned if the subnet overlaps allocated ones.
+func (a *SubnetAllocator) AllocateSpecific(network *net.IPNet) error {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p := a.pool(key, v6)
+	if p == nil {
+		return fmt.Errorf("%w: %s is out of pools", ErrInvalidNetwork, network)
+	}
+	if !p.allocateSpecific(key) {
+		return fmt.Errorf("%w: %s", ErrAllocated, network)
+	}
+	return nil
+}
+
+// Release returns the allocated subnet back to its pool.
+// ErrNotAllocated is returned if exactly this subnet was not allocated.
+func (a *SubnetAllocator) Release(network *net.IPNet) error {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p := a.pool(key, v6)
+	if p == nil || !p.release(key) {
+		return fmt.Errorf("%w: %s", ErrNotAllocated, network)
+	}
+	return nil
+}
+
+// Free returns the sorted list of free blocks of all pools.
+// IPv4 blocks go first.
+func (a *SubnetAllocator) Free() []*net.IPNet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.list(func(p *subnetPool) map[ip6Net]struct{} { return p.free })
+}
+
+// Allocated returns the sorted list of allocated subnets of all pools.
+// IPv4 subnets go first.
+func (a *SubnetAllocator) Allocated() []*net.IPNet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.list(func(p *subnetPool) map[ip6Net]struct{} { return p.used })
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a *SubnetAllocator) MarshalJSON() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var v subnetAllocatorJSON
+	v.Pools = make([]string, 0, len(a.pools))
+	for _, p := range a.pools {
+		v.Pools = append(v.Pools, keyNet(p.root, p.v6).String())
+	}
+	v.Allocated = make([]string, 0)
+	for _, n := range a.list(func(p *subnetPool) map[ip6Net]struct{} { return p.used }) {
+		v.Allocated = append(v.Allocated, n.String())
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The current state of the allocator is replaced.
+func (a *SubnetAllocator) UnmarshalJSON(data []byte) error {
+	var v subnetAllocatorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	out := new(SubnetAllocator)
+	for _, s := range v.Pools {
+		_, pool, err := net.ParseCIDR(s)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidNetwork, s)
+		}
+		if err = out.addPool(pool); err != nil {
+			return err
+		}
+	}
+	for _, s := range v.Allocated {
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidNetwork, s)
+		}
+		if err = out.AllocateSpecific(network); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pools = out.pools
+	return nil
+}
+
+// addPool adds a new pool, it should not overlap existing ones.
+func (a *SubnetAllocator) addPool(pool *net.IPNet) error {
+	key, v6, err := tableKey(pool)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range a.pools {
+		if p.v6 == v6 && (key.subnetOf(p.root) || p.root.subnetOf(key)) {
+			return fmt.Errorf("%w: %s overlaps another pool", ErrInvalidNetwork, pool)
+		}
+	}
+
+	a.pools = append(a.pools, &subnetPool{
+		root: key,
+		v6:   v6,
+		free: map[ip6Net]struct{}{key: {}},
+		used: make(map[ip6Net]struct{}),
+	})
+	return nil
+}
+
+// pool returns the pool containing the block or nil.
+func (a *SubnetAllocator) pool(key ip6Net, v6 bool) *subnetPool {
+	for _, p := range a.pools {
+		if p.v6 == v6 && key.prefix >= p.root.prefix && key.subnetOf(p.root) {
+			return p
+		}
+	}
+	return nil
+}
+
+// list returns sorted blocks of all pools.
+func (a *SubnetAllocator) list(blocks func(*subnetPool) map[ip6Net]struct{}) []*net.IPNet {
+	var four, six ip6Nets
+	for _, p := range a.pools {
+		for key := range blocks(p) {
+			if p.v6 {
+				six = append(six, key)
+			} else {
+				four = append(four, key)
+			}
+		}
+	}
+	sort.Sort(four)
+	sort.Sort(six)
+
+	out := make([]*net.IPNet, 0, len(four)+len(six))
+	for _, key := range four {
+		out = append(out, keyNet(key, false))
+	}
+	for _, key := range six {
+		out = append(out, keyNet(key, true))
+	}
+	return out
+}
+
+// allocate finds the smallest free block which fits the prefix length
+// (the lowest one of the same size) and splits it down to the prefix length.
+func (p *subnetPool) allocate(prefixLen int) (ip6Net, bool) {
+	bits := 8 * net.IPv4len
+	if p.v6 {
+		bits = 8 * net.IPv6len
+	}
+	if prefixLen < int(p.root.prefix) || prefixLen > bits {
+		return ip6Net{}, false // does not fit the pool
+	}
+
+	var best ip6Net
+	found := false
+	for key := range p.free {
+		if int(key.prefix) > prefixLen {
+			continue // too small
+		}
+		if !found || key.prefix > best.prefix ||
+			(key.prefix == best.prefix && key.addr.Cmp(best.addr) < 0) {
+			best, found = key, true
+		}
+	}
+	if !found {
+		return ip6Net{}, false
+	}
+
+	delete(p.free, best)
+	for int(best.prefix) < prefixLen {
+		lo, hi := best.subnets()
+		p.free[hi] = struct{}{}
+		best = lo
+	}
+	p.used[best] = struct{}{}
+	return best, true
+}
+
+// allocateSpecific splits the free block containing the key down to the key.
+func (p *subnetPool) allocateSpecific(key ip6Net) bool {
+	for block := range p.free {
+		if key.prefix < block.prefix || !key.subnetOf(block) {
+			continue
+		}
+
+		delete(p.free, block)
+		for block.prefix < key.prefix {
+			lo, hi := block.subnets()
+			if key.subnetOf(lo) {
+				p.free[hi] = struct{}{}
+				block = lo
+			} else {
+				p.free[lo] = struct{}{}
+				block = hi
+			}
+		}
+		p.used[key] = struct{}{}
+		return true
+	}
+	return false // overlaps allocated blocks
+}
+
+// release frees the allocated block and merges it with free buddies.
+func (p *subnetPool) release(key ip6Net) bool {
+	if _, ok := p.used[key]; !ok {
+		return false
+	}
+
+	delete(p.used, key)
+	for key.prefix > p.root.prefix {
+		lo, hi := key.super().subnets()
+		buddy := lo
+		if buddy == key {
+			buddy = hi
+		}
+		if _, ok := p.free[buddy]; !ok {
+			break
+		}
+		delete(p.free, buddy)
+		key = key.super()
+	}
+	p.free[key] = struct{}{}
+	return true
+}
@@ -0,0 +1,172 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleSubnetAllocator is an example of subnet allocation
+func ExampleSubnetAllocator() {
+	a, _ := ipx.NewSubnetAllocator(cidr("10.0.0.0/24"))
+	n1, _ := a.Allocate(26, false)
+	n2, _ := a.Allocate(28, false)
+	fmt.Println(n1, n2)
+	fmt.Println(a.Free())
+	// Output:
+	// 10.0.0.0/26 10.0.0.64/28
+	// [10.0.0.80/28 10.0.0.96/27 10.0.0.128/25]
+}
+
+// TestSubnetAllocator unit tests for SubnetAllocator
+func TestSubnetAllocator(tt *testing.T) {
+	tt.Run("buddy", func(t *testing.T) {
+		a, err := ipx.NewSubnetAllocator(cidr("10.0.0.0/24"), cidr("2001:db8::/48"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "10.0.0.0/25", mustAllocate(t, a, 25, false).String())
+		assert.Equal(t, "10.0.0.128/27", mustAllocate(t, a, 27, false).String())
+		assert.Equal(t, "10.0.0.192/26", mustAllocate(t, a, 26, false).String())
+		assert.Equal(t, "10.0.0.160/27", mustAllocate(t, a, 27, false).String())
+
+		// no IPv4 space left, the IPv6 pool is not used for IPv4 requests
+		_, err = a.Allocate(27, false)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+		_, err = a.Allocate(32, false)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+
+		assert.Equal(t, "2001:db8::/64", mustAllocate(t, a, 64, true).String())
+		assert.Equal(t, []string{
+			"2001:db8:0:1::/64",
+			"2001:db8:0:2::/63",
+			"2001:db8:0:4::/62",
+			"2001:db8:0:8::/61",
+			"2001:db8:0:10::/60",
+			"2001:db8:0:20::/59",
+			"2001:db8:0:40::/58",
+			"2001:db8:0:80::/57",
+			"2001:db8:0:100::/56",
+			"2001:db8:0:200::/55",
+			"2001:db8:0:400::/54",
+			"2001:db8:0:800::/53",
+			"2001:db8:0:1000::/52",
+			"2001:db8:0:2000::/51",
+			"2001:db8:0:4000::/50",
+			"2001:db8:0:8000::/49",
+		}, netStrings(a.Free()))
+
+		_, err = a.Allocate(47, true)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+	})
+
+	tt.Run("release", func(t *testing.T) {
+		a, err := ipx.NewSubnetAllocator(cidr("10.0.0.0/24"))
+		require.NoError(t, err)
+
+		var nets []string
+		for i := 0; i < 4; i++ {
+			nets = append(nets, mustAllocate(t, a, 26, false).String())
+		}
+		assert.Empty(t, a.Free())
+
+		for _, n := range nets {
+			require.NoError(t, a.Release(cidr(n)))
+		}
+		assert.Equal(t, []string{"10.0.0.0/24"}, netStrings(a.Free()))
+		assert.Empty(t, a.Allocated())
+
+		assert.ErrorIs(t, a.Release(cidr("10.0.0.0/26")), ipx.ErrNotAllocated)
+		assert.ErrorIs(t, a.Release(cidr("10.1.0.0/26")), ipx.ErrNotAllocated)
+		assert.ErrorIs(t, a.Release(nil), ipx.ErrInvalidNetwork)
+	})
+
+	tt.Run("specific", func(t *testing.T) {
+		a, err := ipx.NewSubnetAllocator(cidr("10.0.0.0/24"))
+		require.NoError(t, err)
+
+		require.NoError(t, a.AllocateSpecific(cidr("10.0.0.96/27")))
+		assert.Equal(t, []string{"10.0.0.0/26", "10.0.0.64/27", "10.0.0.128/25"}, netStrings(a.Free()))
+		assert.ErrorIs(t, a.AllocateSpecific(cidr("10.0.0.64/26")), ipx.ErrAllocated)
+		assert.ErrorIs(t, a.AllocateSpecific(cidr("10.0.0.96/27")), ipx.ErrAllocated)
+		assert.ErrorIs(t, a.AllocateSpecific(cidr("10.0.1.0/27")), ipx.ErrInvalidNetwork)
+
+		// the best fit block is used
+		assert.Equal(t, "10.0.0.64/27", mustAllocate(t, a, 27, false).String())
+	})
+
+	tt.Run("pools", func(t *testing.T) {
+		_, err := ipx.NewSubnetAllocator(cidr("10.0.0.0/16"), cidr("10.0.1.0/24"))
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.NewSubnetAllocator(nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+
+		var a ipx.SubnetAllocator
+		_, err = a.Allocate(24, false)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+
+		// each family has its own pools
+		b, err := ipx.NewSubnetAllocator(cidr("2001:db8::/64"))
+		require.NoError(t, err)
+		_, err = b.Allocate(24, false)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+		assert.Equal(t, "2001:db8::/120", mustAllocate(t, b, 120, true).String())
+	})
+
+	tt.Run("json", func(t *testing.T) {
+		a, err := ipx.NewSubnetAllocator(cidr("10.0.0.0/24"), cidr("2001:db8::/64"))
+		require.NoError(t, err)
+		mustAllocate(t, a, 26, false)
+		require.NoError(t, a.AllocateSpecific(cidr("2001:db8::8000:0:0:0/65")))
+
+		data, err := json.Marshal(a)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"pools":["10.0.0.0/24","2001:db8::/64"],"allocated":["10.0.0.0/26","2001:db8:0:0:8000::/65"]}`, string(data))
+
+		var b ipx.SubnetAllocator
+		require.NoError(t, json.Unmarshal(data, &b))
+		assert.Equal(t, netStrings(a.Free()), netStrings(b.Free()))
+		assert.Equal(t, netStrings(a.Allocated()), netStrings(b.Allocated()))
+
+		assert.Error(t, json.Unmarshal([]byte(`{"pools":["foo"]}`), &b))
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"pools":["10.0.0.0/24"],"allocated":["10.0.0.0/25","10.0.0.0/26"]}`), &b), ipx.ErrAllocated)
+	})
+
+	tt.Run("concurrent", func(t *testing.T) {
+		a, err := ipx.NewSubnetAllocator(cidr("10.0.0.0/16"))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		seen := make(map[string]bool)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 32; k++ {
+					n, err := a.Allocate(24, false)
+					if err != nil {
+						return
+					}
+					mu.Lock()
+					seen[n.String()] = true
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Len(t, seen, 256)
+		assert.Empty(t, a.Free())
+	})
+}
+
+// mustAllocate allocates a subnet or fails the test.
+func mustAllocate(t *testing.T, a *ipx.SubnetAllocator, prefixLen int, v6 bool) fmt.Stringer {
+	n, err := a.Allocate(prefixLen, v6)
+	require.NoError(t, err)
+	return n
+}
@@ -51,9 +51,9 @@ func SummarizeRange(first, last net.IP) ([]*net.IPNet, error) {
 
 	switch {
 	case firstV4 != nil && lastV4 != nil:
-		return summarizeRange4(load32(firstV4), load32(lastV4)), nil
+		return summarizeRange4(load32(firstV4), load32(lastV4)).asNets(), nil
 	case firstV6 != nil && lastV6 != nil:
-		return summarizeRange6(load128(firstV6), load128(lastV6)), nil
+		return summarizeRange6(load128(firstV6), load128(lastV6)).asNets(), nil
 	}
 
 	return nil, ErrVersionMismatch
@@ -61,7 +61,7 @@ func SummarizeRange(first, last net.IP) ([]*net.IPNet, error) {
 
 // summarizeRange4 returns a series of IPv4 networks which cover the range
 // between the first and last IPv4 addresses, inclusive.
-func summarizeRange4(first, last uint32) (networks []*net.IPNet) {
+func summarizeRange4(first, last uint32) (networks ip4Nets) {
 	for first <= last {
 		// the network will either be as long as all the trailing zeros of the first address OR the number of bits
 		// necessary to cover the distance between first and last address -- whichever is smaller
@@ -77,14 +77,7 @@ func summarizeRange4(first, last uint32) (networks []*net.IPNet) {
 			}
 		}
 
-		nwkMask := net.CIDRMask(32-nBits, 32)
-		nwkIP := make(net.IP, net.IPv4len)
-		store32(first, nwkIP)
-		networks = append(networks,
-			&net.IPNet{
-				IP:   nwkIP,
-				Mask: nwkMask,
-			})
+		networks = append(networks, ip4Net{addr: first, prefix: uint8(32 - nBits)})
 
 		first += 1 << nBits
 		if first == 0 {
@@ -97,7 +90,7 @@ func summarizeRange4(first, last uint32) (networks []*net.IPNet) {
 
 // summarizeRange6 returns a series of IPv6 networks which cover the range
 // between the first and last IPv6 addresses, inclusive.
-func summarizeRange6(first, last Uint128) (networks []*net.IPNet) {
+func summarizeRange6(first, last Uint128) (networks ip6Nets) {
 	for first.Cmp(last) <= 0 { // first <= last
 		// the network will either be as long as all the trailing zeros of the first address OR the number of bits
 		// necessary to cover the distance between first and last address -- whichever is smaller
@@ -114,14 +107,7 @@ func summarizeRange6(first, last Uint128) (networks []*net.IPNet) {
 			}
 		}
 
-		nwkMask := net.CIDRMask(128-nBits, 128)
-		nwkIP := make(net.IP, net.IPv6len)
-		store128(first, nwkIP)
-		networks = append(networks,
-			&net.IPNet{
-				IP:   nwkIP,
-				Mask: nwkMask,
-			})
+		networks = append(networks, ip6Net{addr: first, prefix: uint8(128 - nBits)})
 
 		first = first.Add(Uint128{Lo: 1}.Lsh(uint(nBits)))
 		if first.IsZero() {
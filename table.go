@@ -0,0 +1,332 @@
+package ipx
+
+import (
+	"net"
+)
+
+// Table is a prefix trie (compressed radix tree) mapping IP networks to values.
+// IPv4 and IPv6 networks are kept in the same table but never match each other.
+//
+// The zero value is an empty table ready to use.
+// Table is not safe for concurrent modification.
+type Table[V any] struct {
+	root4 *tableNode[V]
+	root6 *tableNode[V]
+	size  int
+}
+
+// TableEntry is a network and its value stored in a Table.
+type TableEntry[V any] struct {
+	Network *net.IPNet
+	Value   V
+}
+
+// tableNode is a node of the compressed trie.
+// Nodes without a value (`set == false`) are glue nodes
+// that always have both children.
+type tableNode[V any] struct {
+	key   ip6Net
+	child [2]*tableNode[V]
+	value V
+	set   bool
+}
+
+// Len returns the number of networks stored in the table.
+func (t *Table[V]) Len() int {
+	return t.size
+}
+
+// Insert adds the network to the table or replaces the value of the existing one.
+// Host bits of the network address are ignored.
+func (t *Table[V]) Insert(network *net.IPNet, value V) error {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return err
+	}
+
+	p := t.root(v6)
+	for {
+		n := *p
+		if n == nil {
+			*p = &tableNode[V]{key: key, value: value, set: true}
+			t.size++
+			return nil
+		}
+
+		common := key.commonLen(n.key)
+		switch {
+		case common == n.key.prefix && common == key.prefix:
+			// exact match, just update the value
+			if !n.set {
+				t.size++
+			}
+			n.value, n.set = value, true
+			return nil
+
+		case common == n.key.prefix:
+			// the node covers the key, go deeper
+			p = &n.child[key.bit(n.key.prefix)]
+			continue
+
+		case common == key.prefix:
+			// the key covers the node, insert new node above
+			nn := &tableNode[V]{key: key, value: value, set: true}
+			nn.child[n.key.bit(key.prefix)] = n
+			*p = nn
+
+		default:
+			// the key and the node diverge, insert glue node
+			glue := &tableNode[V]{key: key.truncate(common)}
+			glue.child[key.bit(common)] = &tableNode[V]{key: key, value: value, set: true}
+			glue.child[n.key.bit(common)] = n
+			*p = glue
+		}
+
+		t.size++
+		return nil
+	}
+}
+
+// Delete removes the network from the table.
+// Returns false if the network was not found.
+func (t *Table[V]) Delete(network *net.IPNet) bool {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return false
+	}
+
+	var parent **tableNode[V]
+	p := t.root(v6)
+	for {
+		n := *p
+		if n == nil || n.key.prefix > key.prefix || !key.subnetOf(n.key) {
+			return false // not found
+		}
+		if n.key.prefix == key.prefix {
+			break // found
+		}
+		parent, p = p, &n.child[key.bit(n.key.prefix)]
+	}
+
+	n := *p
+	if !n.set {
+		return false // glue node
+	}
+
+	var zero V
+	n.value, n.set = zero, false
+	t.size--
+
+	// compact the trie
+	switch {
+	case n.child[0] != nil && n.child[1] != nil:
+		// keep as glue node
+	case n.child[0] != nil:
+		*p = n.child[0]
+	case n.child[1] != nil:
+		*p = n.child[1]
+	default:
+		*p = nil
+		if parent != nil && !(*parent).set {
+			// glue node with single child is not needed anymore
+			pn := *parent
+			if pn.child[0] != nil {
+				*parent = pn.child[0]
+			} else {
+				*parent = pn.child[1]
+			}
+		}
+	}
+
+	return true
+}
+
+// Get returns the value of exactly matching network.
+func (t *Table[V]) Get(network *net.IPNet) (value V, ok bool) {
+	key, v6, err := tableKey(network)
+	if err != nil {
+		return // bad network
+	}
+
+	for n := *t.root(v6); n != nil; n = n.child[key.bit(n.key.prefix)] {
+		if n.key.prefix > key.prefix || !key.subnetOf(n.key) {
+			break
+		}
+		if n.key.prefix == key.prefix {
+			return n.value, n.set
+		}
+	}
+
+	return // not found
+}
+
+// LookupLPM returns the longest prefix matching the IP address.
+func (t *Table[V]) LookupLPM(addr net.IP) (network *net.IPNet, value V, ok bool) {
+	var last *tableNode[V]
+	v6 := t.lookup(addr, func(n *tableNode[V]) {
+		last = n
+	})
+	if last == nil {
+		return // not found
+	}
+
+	return last.network(v6), last.value, true
+}
+
+// LookupAll returns all networks containing the IP address.
+// Entries are ordered from the shortest prefix to the longest one.
+func (t *Table[V]) LookupAll(addr net.IP) []TableEntry[V] {
+	var found []*tableNode[V]
+	v6 := t.lookup(addr, func(n *tableNode[V]) {
+		found = append(found, n)
+	})
+	if len(found) == 0 {
+		return nil // not found
+	}
+
+	out := make([]TableEntry[V], 0, len(found))
+	for _, n := range found {
+		out = append(out, TableEntry[V]{
+			Network: n.network(v6),
+			Value:   n.value,
+		})
+	}
+
+	return out
+}
+
+// Walk calls fn for each network in the table in order:
+// IPv4 networks first, then IPv6 networks, both sorted by address
+// and then by prefix length. Walking stops if fn returns false.
+func (t *Table[V]) Walk(fn func(network *net.IPNet, value V) bool) {
+	if walkTable(t.root4, false, fn) {
+		walkTable(t.root6, true, fn)
+	}
+}
+
+// root returns the root of IPv4 or IPv6 trie.
+func (t *Table[V]) root(v6 bool) **tableNode[V] {
+	if v6 {
+		return &t.root6
+	}
+	return &t.root4
+}
+
+// lookup calls fn for each node with value containing the IP address,
+// from the shortest prefix to the longest one.
+func (t *Table[V]) lookup(addr net.IP, fn func(*tableNode[V])) (v6 bool) {
+	var key ip6Net
+	if v4 := addr.To4(); v4 != nil {
+		key = ip4Net{addr: load32(v4), prefix: 32}.wide()
+	} else if a6 := addr.To16(); a6 != nil {
+		key = ip6Net{addr: load128(a6), prefix: 128}
+		v6 = true
+	} else {
+		return // bad address
+	}
+
+	for n := *t.root(v6); n != nil; n = n.child[key.bit(n.key.prefix)] {
+		if !key.subnetOf(n.key) {
+			break
+		}
+		if n.set {
+			fn(n)
+		}
+		if n.key.prefix == key.prefix {
+			break
+		}
+	}
+
+	return
+}
+
+// walkTable walks the trie in pre-order.
+// Returns false if walking has been stopped.
+func walkTable[V any](n *tableNode[V], v6 bool, fn func(*net.IPNet, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.set && !fn(n.network(v6), n.value) {
+		return false
+	}
+	return walkTable(n.child[0], v6, fn) &&
+		walkTable(n.child[1], v6, fn)
+}
+
+// network returns the node key as IP network.
+func (n *tableNode[V]) network(v6 bool) *net.IPNet {
+	return keyNet(n.key, v6)
+}
+
+// keyNet converts the key made by tableKey() back to IP network.
+func keyNet(key ip6Net, v6 bool) *net.IPNet {
+	if v6 {
+		return key.asNet()
+	}
+	return key.narrow().asNet()
+}
+
+// tableKey converts the network into the trie key.
+// IPv4 networks are aligned to the most significant bits,
+// so both IP versions share the same bit layout.
+func tableKey(network *net.IPNet) (key ip6Net, v6 bool, err error) {
+	if network == nil {
+		return key, false, ErrInvalidNetwork
+	}
+
+	ones, bits := network.Mask.Size()
+	if v4 := network.IP.To4(); v4 != nil {
+		if bits == 8*net.IPv6len && ones >= 96 && len(network.IP) == net.IPv6len {
+			ones -= 96 // IPv4-mapped IPv6 network
+		} else if bits != 8*net.IPv4len {
+			return key, false, ErrInvalidNetwork
+		}
+
+		n := ip4Net{addr: load32(v4), prefix: uint8(ones)}
+		n.addr &= n.mask()
+		return n.wide(), false, nil
+	}
+
+	if v6 := network.IP.To16(); v6 != nil && bits == 8*net.IPv6len {
+		n := ip6Net{addr: load128(v6), prefix: uint8(ones)}
+		n.addr = n.addr.And(n.mask())
+		return n, true, nil
+	}
+
+	return key, false, ErrInvalidNetwork
+}
+
+// wide converts IPv4 network into IPv6 network
+// aligned to the most significant bits.
+func (n ip4Net) wide() ip6Net {
+	return ip6Net{addr: Uint128{Hi: uint64(n.addr) << 32}, prefix: n.prefix}
+}
+
+// narrow converts IPv6 network made by wide() back to IPv4 network.
+func (n ip6Net) narrow() ip4Net {
+	return ip4Net{addr: uint32(n.addr.Hi >> 32), prefix: n.prefix}
+}
+
+// bit returns the i-th most significant bit of the network address.
+func (n ip6Net) bit(i uint8) int {
+	return int(n.addr.Rsh(127-uint(i)).Lo & 1)
+}
+
+// commonLen returns the length of the common prefix of both networks.
+func (n ip6Net) commonLen(o ip6Net) uint8 {
+	common := uint8(n.addr.Xor(o.addr).LeadingZeros())
+	if common > n.prefix {
+		common = n.prefix
+	}
+	if common > o.prefix {
+		common = o.prefix
+	}
+	return common
+}
+
+// truncate returns the supernet with the given prefix length.
+func (n ip6Net) truncate(prefix uint8) ip6Net {
+	n.prefix = prefix
+	n.addr = n.addr.And(n.mask())
+	return n
+}
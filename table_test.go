@@ -0,0 +1,185 @@
+package ipx_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleTable is an example of Table longest prefix match lookup
+func ExampleTable() {
+	var t ipx.Table[string]
+	_ = t.Insert(cidr("10.0.0.0/8"), "corp")
+	_ = t.Insert(cidr("10.1.0.0/16"), "lab")
+	_ = t.Insert(cidr("2001:db8::/32"), "docs")
+
+	n, v, _ := t.LookupLPM(net.ParseIP("10.1.2.3"))
+	fmt.Println(n, v)
+	n, v, _ = t.LookupLPM(net.ParseIP("10.2.0.1"))
+	fmt.Println(n, v)
+	n, v, _ = t.LookupLPM(net.ParseIP("2001:db8::1"))
+	fmt.Println(n, v)
+	// Output:
+	// 10.1.0.0/16 lab
+	// 10.0.0.0/8 corp
+	// 2001:db8::/32 docs
+}
+
+// tableNetworks returns all table networks in walk order.
+func tableNetworks(t *ipx.Table[int]) []string {
+	var out []string
+	t.Walk(func(n *net.IPNet, v int) bool {
+		out = append(out, fmt.Sprintf("%s=%d", n, v))
+		return true
+	})
+	return out
+}
+
+// TestTable unit tests for Table
+func TestTable(tt *testing.T) {
+	tt.Run("bad", func(t *testing.T) {
+		var tbl ipx.Table[int]
+		assert.ErrorIs(t, tbl.Insert(nil, 1), ipx.ErrInvalidNetwork)
+		assert.ErrorIs(t, tbl.Insert(&net.IPNet{IP: make(net.IP, 3), Mask: net.CIDRMask(8, 32)}, 1), ipx.ErrInvalidNetwork)
+		assert.False(t, tbl.Delete(nil))
+		_, ok := tbl.Get(nil)
+		assert.False(t, ok)
+		_, _, ok = tbl.LookupLPM(net.ParseIP("bad"))
+		assert.False(t, ok)
+		assert.Zero(t, tbl.Len())
+	})
+
+	tt.Run("insert_get", func(t *testing.T) {
+		var tbl ipx.Table[int]
+		for i, s := range []string{
+			"10.0.0.0/8", "10.1.0.0/16", "10.0.0.0/16", "192.168.0.0/24",
+			"0.0.0.0/0", "2001:db8::/32", "2001:db8:1::/48", "::/0",
+		} {
+			require.NoError(t, tbl.Insert(cidr(s), i))
+		}
+		assert.Equal(t, 8, tbl.Len())
+
+		v, ok := tbl.Get(cidr("10.1.0.0/16"))
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		_, ok = tbl.Get(cidr("10.1.0.0/24"))
+		assert.False(t, ok)
+
+		// host bits are ignored
+		v, ok = tbl.Get(&net.IPNet{IP: net.ParseIP("10.1.2.3"), Mask: net.CIDRMask(16, 32)})
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		// update
+		require.NoError(t, tbl.Insert(cidr("10.1.0.0/16"), 100))
+		assert.Equal(t, 8, tbl.Len())
+		v, _ = tbl.Get(cidr("10.1.0.0/16"))
+		assert.Equal(t, 100, v)
+
+		assert.Equal(t, []string{
+			"0.0.0.0/0=4",
+			"10.0.0.0/8=0",
+			"10.0.0.0/16=2",
+			"10.1.0.0/16=100",
+			"192.168.0.0/24=3",
+			"::/0=7",
+			"2001:db8::/32=5",
+			"2001:db8:1::/48=6",
+		}, tableNetworks(&tbl))
+	})
+
+	tt.Run("lookup", func(t *testing.T) {
+		var tbl ipx.Table[int]
+		require.NoError(t, tbl.Insert(cidr("10.0.0.0/8"), 8))
+		require.NoError(t, tbl.Insert(cidr("10.1.0.0/16"), 16))
+		require.NoError(t, tbl.Insert(cidr("10.1.1.0/24"), 24))
+		require.NoError(t, tbl.Insert(cidr("10.1.1.1/32"), 32))
+		require.NoError(t, tbl.Insert(cidr("::/0"), 0))
+
+		n, v, ok := tbl.LookupLPM(net.ParseIP("10.1.1.1"))
+		require.True(t, ok)
+		assert.Equal(t, "10.1.1.1/32", n.String())
+		assert.Equal(t, 32, v)
+
+		n, v, ok = tbl.LookupLPM(net.ParseIP("10.1.2.1"))
+		require.True(t, ok)
+		assert.Equal(t, "10.1.0.0/16", n.String())
+		assert.Equal(t, 16, v)
+
+		_, _, ok = tbl.LookupLPM(net.ParseIP("11.0.0.1"))
+		assert.False(t, ok)
+
+		// IPv6 never matches IPv4
+		n, v, ok = tbl.LookupLPM(net.ParseIP("2001:db8::1"))
+		require.True(t, ok)
+		assert.Equal(t, "::/0", n.String())
+		assert.Equal(t, 0, v)
+
+		all := tbl.LookupAll(net.ParseIP("10.1.1.1"))
+		require.Len(t, all, 4)
+		for i, s := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.1.0/24", "10.1.1.1/32"} {
+			assert.Equal(t, s, all[i].Network.String())
+		}
+		assert.Nil(t, tbl.LookupAll(net.ParseIP("192.0.2.1")))
+	})
+
+	tt.Run("delete", func(t *testing.T) {
+		var tbl ipx.Table[int]
+		for i, s := range []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.0.0/16", "10.0.2.0/24"} {
+			require.NoError(t, tbl.Insert(cidr(s), i))
+		}
+
+		assert.False(t, tbl.Delete(cidr("10.0.0.0/23"))) // glue node
+		assert.False(t, tbl.Delete(cidr("10.0.3.0/24")))
+		assert.True(t, tbl.Delete(cidr("10.0.0.0/16")))
+		assert.False(t, tbl.Delete(cidr("10.0.0.0/16")))
+		assert.Equal(t, 3, tbl.Len())
+
+		_, _, ok := tbl.LookupLPM(net.ParseIP("10.0.5.1"))
+		assert.False(t, ok)
+
+		assert.True(t, tbl.Delete(cidr("10.0.0.0/24")))
+		assert.True(t, tbl.Delete(cidr("10.0.2.0/24")))
+		assert.Equal(t, []string{"10.0.1.0/24=1"}, tableNetworks(&tbl))
+
+		assert.True(t, tbl.Delete(cidr("10.0.1.0/24")))
+		assert.Nil(t, tableNetworks(&tbl))
+		assert.Zero(t, tbl.Len())
+	})
+
+	tt.Run("walk_stop", func(t *testing.T) {
+		var tbl ipx.Table[int]
+		require.NoError(t, tbl.Insert(cidr("10.0.0.0/8"), 1))
+		require.NoError(t, tbl.Insert(cidr("2001:db8::/32"), 2))
+
+		count := 0
+		tbl.Walk(func(*net.IPNet, int) bool {
+			count++
+			return false
+		})
+		assert.Equal(t, 1, count)
+	})
+}
+
+// BenchmarkTableLookupLPM performance benchmarks for Table.LookupLPM
+func BenchmarkTableLookupLPM(bb *testing.B) {
+	var tbl ipx.Table[int]
+	for i := 0; i < 1024; i++ {
+		_ = tbl.Insert(&net.IPNet{
+			IP:   net.IPv4(10, byte(i>>8), byte(i), 0).To4(),
+			Mask: net.CIDRMask(24, 32),
+		}, i)
+	}
+	addr := net.ParseIP("10.3.7.1")
+
+	bb.ReportAllocs()
+	bb.ResetTimer()
+	for i := 0; i < bb.N; i++ {
+		_, _, _ = tbl.LookupLPM(addr)
+	}
+}
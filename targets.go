@@ -0,0 +1,347 @@
+package ipx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Targets is a set of scan targets defined by Nmap-style specification.
+//
+// The following target notations are supported:
+//   - `10.0.0.1` or `2001:db8::1` single address,
+//   - `10.1.1.0/24` or `2001:db8::/120` CIDR notation,
+//   - `192.168.0-3.1-254` octet ranges, `10.0.0.*` wildcards
+//     and `10.0.0.1,3,5-7` octet lists,
+//   - `10.0.0.1-10.0.0.50` and other notations accepted by ParseRange().
+//
+// Targets are separated by whitespace or commas.
+type Targets struct {
+	set RangeSet
+}
+
+// TargetError is a target specification error.
+type TargetError struct {
+	Token  string // bad token
+	Line   int    // line number, starting from 1; zero if not applicable
+	Offset int    // byte offset of the token in the line or specification
+	Err    error  // underlying error
+}
+
+// Error implements the error interface.
+func (e *TargetError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d, offset %d: %q: %v", e.Line, e.Offset, e.Token, e.Err)
+	}
+	return fmt.Sprintf("offset %d: %q: %v", e.Offset, e.Token, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// ParseTargets parses the target specification.
+func ParseTargets(spec string) (*Targets, error) {
+	t := new(Targets)
+	if err := t.Add(spec); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Add adds all targets of the specification.
+func (t *Targets) Add(spec string) error {
+	four, six, err := parseTargetSpans(spec, 0)
+	if err != nil {
+		return err
+	}
+
+	t.set.v4 = t.set.v4.union(four)
+	t.set.v6 = t.set.v6.union(six)
+	return nil
+}
+
+// Exclude removes all targets of the specification.
+func (t *Targets) Exclude(spec string) error {
+	return t.exclude(spec, 0)
+}
+
+// ExcludeFile removes all targets listed in the exclude file.
+// Each line may contain several targets, `#` starts a comment.
+func (t *Targets) ExcludeFile(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i] // cut comment
+		}
+		if err := t.exclude(text, line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// RangeSet returns all target addresses as a set of IP ranges.
+func (t *Targets) RangeSet() *RangeSet {
+	return &RangeSet{v4: t.set.v4, v6: t.set.v6}
+}
+
+// Addresses returns all target addresses.
+// Each address is visited once, in ascending order.
+func (t *Targets) Addresses() *RangeSetIter {
+	return t.set.Addresses()
+}
+
+// exclude removes all targets of the specification.
+func (t *Targets) exclude(spec string, line int) error {
+	four, six, err := parseTargetSpans(spec, line)
+	if err != nil {
+		return err
+	}
+
+	t.set.v4 = t.set.v4.subtract(four)
+	t.set.v6 = t.set.v6.subtract(six)
+	return nil
+}
+
+// parseTargetSpans parses the specification into normalized IPv4 and IPv6 spans.
+func parseTargetSpans(spec string, line int) (four, six ipSpans, err error) {
+	var v4s, v6s []ipSpan
+	err = parseTargets(spec, line, func(s ipSpan, v6 bool) {
+		if v6 {
+			v6s = append(v6s, s)
+		} else {
+			v4s = append(v4s, s)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return normalizeSpans(v4s), normalizeSpans(v6s), nil
+}
+
+// parseTargets parses the specification calling fn for each span of addresses.
+func parseTargets(spec string, line int, fn func(ipSpan, bool)) error {
+	for start := 0; start < len(spec); {
+		// skip separators
+		if r := rune(spec[start]); unicode.IsSpace(r) || r == ',' {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(spec) && !unicode.IsSpace(rune(spec[end])) {
+			end++
+		}
+
+		if err := parseTargetToken(spec[start:end], start, line, fn); err != nil {
+			return err
+		}
+		start = end
+	}
+
+	return nil
+}
+
+// parseTargetToken parses whitespace separated token.
+// The token may contain comma separated list of targets
+// unless commas are part of IPv4 octet lists.
+func parseTargetToken(token string, offset, line int, fn func(ipSpan, bool)) error {
+	if spans, ok, err := parseOctets(token); ok {
+		if err != nil {
+			return &TargetError{Token: token, Line: line, Offset: offset, Err: err}
+		}
+		for _, s := range spans {
+			fn(s, false)
+		}
+		return nil
+	}
+
+	for len(token) > 0 {
+		item := token
+		if i := strings.IndexByte(token, ','); i >= 0 {
+			item = token[:i]
+		}
+
+		if item != "" {
+			if err := parseTarget(item, fn); err != nil {
+				return &TargetError{Token: item, Line: line, Offset: offset, Err: err}
+			}
+		}
+
+		offset += len(item) + 1
+		if len(item) >= len(token) {
+			break
+		}
+		token = token[len(item)+1:]
+	}
+
+	return nil
+}
+
+// parseTarget parses single target.
+func parseTarget(target string, fn func(ipSpan, bool)) error {
+	if spans, ok, err := parseOctets(target); ok {
+		if err != nil {
+			return err
+		}
+		for _, s := range spans {
+			fn(s, false)
+		}
+		return nil
+	}
+
+	r, err := ParseRange(target)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+
+	span, v6, err := rangeSpan(r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+
+	fn(span, v6)
+	return nil
+}
+
+// maxOctetSpans limits the number of address spans a single octet expression
+// may expand to, e.g. `10.*.*.1` expands to 65536 spans while `*.*.*.1` is rejected.
+const maxOctetSpans = 1 << 16
+
+// octetSpan is an inclusive range of octet values.
+type octetSpan struct {
+	lo, hi uint32
+}
+
+// parseOctets parses IPv4 octet expression like `192.168.0-3,5.*`.
+// Returns false if the input is not an octet expression
+// and ErrInvalidTarget if it expands to more than maxOctetSpans spans.
+func parseOctets(s string) ([]ipSpan, bool, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != net.IPv4len {
+		return nil, false, nil
+	}
+
+	var octets [net.IPv4len][]octetSpan
+	for i, p := range parts {
+		spans, ok := parseOctet(p)
+		if !ok {
+			return nil, false, nil
+		}
+		octets[i] = spans
+	}
+
+	// the leading octets are expanded value by value,
+	// the first octet followed by full octets only emits its spans
+	count := 1
+	for depth, o := range octets {
+		full := true
+		for _, r := range octets[depth+1:] {
+			full = full && len(r) == 1 && r[0] == octetSpan{0, 255}
+		}
+		if full {
+			count *= len(o)
+			break
+		}
+		values := 0
+		for _, r := range o {
+			values += int(r.hi - r.lo + 1)
+		}
+		if count *= values; count > maxOctetSpans {
+			break
+		}
+	}
+	if count > maxOctetSpans {
+		return nil, true, fmt.Errorf("%w: expands to more than %d ranges", ErrInvalidTarget, maxOctetSpans)
+	}
+
+	var out []ipSpan
+	var emit func(prefix uint32, depth int)
+	emit = func(prefix uint32, depth int) {
+		// if the rest octets are full, the spans of this octet are contiguous
+		full := true
+		for _, o := range octets[depth+1:] {
+			full = full && len(o) == 1 && o[0] == octetSpan{0, 255}
+		}
+
+		shift := 8 * uint(net.IPv4len-1-depth)
+		for _, o := range octets[depth] {
+			if full {
+				out = append(out, ipSpan{
+					first: Uint128{Lo: uint64((prefix<<8 | o.lo) << shift)},
+					last:  Uint128{Lo: uint64((prefix<<8|o.hi)<<shift | (1<<shift - 1))},
+				})
+				continue
+			}
+			for v := o.lo; v <= o.hi; v++ {
+				emit(prefix<<8|v, depth+1)
+			}
+		}
+	}
+	emit(0, 0)
+
+	return out, true, nil
+}
+
+// parseOctet parses single octet expression like `*`, `1-5,7` or `-10`.
+func parseOctet(s string) ([]octetSpan, bool) {
+	if s == "*" {
+		return []octetSpan{{0, 255}}, true
+	}
+
+	var out []octetSpan
+	for _, item := range strings.Split(s, ",") {
+		lo, hi := item, item
+		if i := strings.IndexByte(item, '-'); i >= 0 {
+			lo, hi = item[:i], item[i+1:]
+			if lo == "" {
+				lo = "0" // `-m` form
+			}
+			if hi == "" {
+				hi = "255" // `n-` form
+			}
+		}
+
+		l, err := strconv.ParseUint(lo, 10, 8)
+		if err != nil {
+			return nil, false
+		}
+		h, err := strconv.ParseUint(hi, 10, 8)
+		if err != nil || l > h {
+			return nil, false
+		}
+
+		out = append(out, octetSpan{uint32(l), uint32(h)})
+	}
+
+	return mergeOctets(out), true
+}
+
+// mergeOctets sorts octet spans and merges overlapping or adjacent ones.
+func mergeOctets(spans []octetSpan) []octetSpan {
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].lo < spans[j].lo
+	})
+
+	out := spans[:1]
+	for _, s := range spans[1:] {
+		last := &out[len(out)-1]
+		if s.lo > last.hi+1 {
+			out = append(out, s)
+		} else if s.hi > last.hi {
+			last.hi = s.hi
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,138 @@
+package ipx_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleParseTargets is an example of ParseTargets
+func ExampleParseTargets() {
+	t, _ := ipx.ParseTargets("192.168.0-1.1-2, 10.0.0.*")
+	_ = t.Exclude("10.0.0.2-255")
+	for iter := t.Addresses(); iter.Next(); {
+		fmt.Println(iter.IP())
+	}
+	// Output:
+	// 10.0.0.0
+	// 10.0.0.1
+	// 192.168.0.1
+	// 192.168.0.2
+	// 192.168.1.1
+	// 192.168.1.2
+}
+
+// targetRanges parses the specification and returns target ranges as strings.
+func targetRanges(t *testing.T, spec string) []string {
+	targets, err := ipx.ParseTargets(spec)
+	require.NoError(t, err, spec)
+	return rangeStrings(targets.RangeSet().Ranges())
+}
+
+// TestParseTargets unit tests for ParseTargets
+func TestParseTargets(tt *testing.T) {
+	tt.Run("good", func(t *testing.T) {
+		for _, c := range []struct {
+			spec     string
+			expected []string
+		}{
+			{"", nil},
+			{"10.0.0.1", []string{"10.0.0.1-10.0.0.1"}},
+			{"10.0.0.*", []string{"10.0.0.0-10.0.0.255"}},
+			{"10.*.*.*", []string{"10.0.0.0-10.255.255.255"}},
+			{"10.1.1.0/24", []string{"10.1.1.0-10.1.1.255"}},
+			{"192.168.0-3.1-254", []string{
+				"192.168.0.1-192.168.0.254",
+				"192.168.1.1-192.168.1.254",
+				"192.168.2.1-192.168.2.254",
+				"192.168.3.1-192.168.3.254",
+			}},
+			{"192.168.0-1.*", []string{"192.168.0.0-192.168.1.255"}},
+			{"10.0.0.1,3,5-7", []string{"10.0.0.1-10.0.0.1", "10.0.0.3-10.0.0.3", "10.0.0.5-10.0.0.7"}},
+			{"10.0.0.-2", []string{"10.0.0.0-10.0.0.2"}},
+			{"10.0.0.250-", []string{"10.0.0.250-10.0.0.255"}},
+			{"10.0.0.1,10.0.0.5", []string{"10.0.0.1-10.0.0.1", "10.0.0.5-10.0.0.5"}},
+			{"10.0.0.1-10.0.0.5 10.0.0.3-10.0.0.9", []string{"10.0.0.1-10.0.0.9"}},
+			{"2001:db8::/126,2001:db8::1", []string{"2001:db8::-2001:db8::3"}},
+			{"10.0.0.0/31\n\t2001:db8::1-ff", []string{"10.0.0.0-10.0.0.1", "2001:db8::1-2001:db8::ff"}},
+		} {
+			assert.Equal(t, c.expected, targetRanges(t, c.spec), c.spec)
+		}
+
+		// the largest allowed octet expansion
+		assert.Len(t, targetRanges(t, "10.*.*.1"), 1<<16)
+	})
+
+	tt.Run("bad", func(t *testing.T) {
+		for _, c := range []struct {
+			spec   string
+			token  string
+			offset int
+		}{
+			{"10.0.0.256", "10.0.0.256", 0},
+			{"10.0.0.1 10.0.0.5-3", "10.0.0.5-3", 9},
+			{"10.0.0.1,bad", "bad", 9},
+			{"10.0.0.1, 10.0.0.2,10.0.0.0/33", "10.0.0.0/33", 19},
+			{"2001:db8::/129", "2001:db8::/129", 0},
+			{"10.0.0.1 *.*.*.1", "*.*.*.1", 9},
+			{"1-254.1-254.1-254.1", "1-254.1-254.1-254.1", 0},
+		} {
+			_, err := ipx.ParseTargets(c.spec)
+			var terr *ipx.TargetError
+			if assert.ErrorAs(t, err, &terr, c.spec) {
+				assert.ErrorIs(t, err, ipx.ErrInvalidTarget)
+				assert.Equal(t, c.token, terr.Token, c.spec)
+				assert.Equal(t, c.offset, terr.Offset, c.spec)
+				assert.Zero(t, terr.Line)
+				assert.Contains(t, err.Error(), fmt.Sprintf("offset %d", c.offset))
+			}
+		}
+	})
+}
+
+// TestTargetsExclude unit tests for Targets.Exclude and Targets.ExcludeFile
+func TestTargetsExclude(t *testing.T) {
+	targets, err := ipx.ParseTargets("10.0.0.0/24 2001:db8::/120")
+	require.NoError(t, err)
+
+	require.NoError(t, targets.Exclude("10.0.0.0,255"))
+	require.NoError(t, targets.ExcludeFile(strings.NewReader(`
+# gateways
+10.0.0.1 2001:db8::1 # inline comment
+10.0.0.128/25
+
+2001:db8::80-ff
+`)))
+	assert.Equal(t, []string{"10.0.0.2-10.0.0.127", "2001:db8::-2001:db8::", "2001:db8::2-2001:db8::7f"},
+		rangeStrings(targets.RangeSet().Ranges()))
+
+	err = targets.ExcludeFile(strings.NewReader("10.0.0.1\n\n  10.0.0.1,10.0.0.x\n"))
+	var terr *ipx.TargetError
+	require.ErrorAs(t, err, &terr)
+	assert.Equal(t, 3, terr.Line)
+	assert.Equal(t, 11, terr.Offset)
+	assert.Equal(t, "10.0.0.x", terr.Token)
+	assert.Contains(t, err.Error(), "line 3, offset 11")
+}
+
+// TestTargetsAddresses unit tests for Targets.Addresses
+func TestTargetsAddresses(t *testing.T) {
+	targets, err := ipx.ParseTargets("10.0.0.254-255 10.0.1.0/31 10.0.0.255 ::1")
+	require.NoError(t, err)
+
+	var got []string
+	for iter := targets.Addresses(); iter.Next(); {
+		got = append(got, iter.IP().String())
+	}
+	assert.Equal(t, []string{"10.0.0.254", "10.0.0.255", "10.0.1.0", "10.0.1.1", "::1"}, got)
+
+	empty, err := ipx.ParseTargets("")
+	require.NoError(t, err)
+	iter := empty.Addresses()
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.IP())
+}
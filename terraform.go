@@ -0,0 +1,114 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+)
+
+// CIDRSubnet returns the netnum-th subnet of the prefix extended by newbits,
+// the same as Terraform's cidrsubnet(prefix, newbits, netnum) function.
+// Host bits of the prefix are ignored.
+func CIDRSubnet(prefix *net.IPNet, newbits, netnum int) (*net.IPNet, error) {
+	base, ones, bits, err := cidrBase(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case newbits < 0 || newbits > 32:
+		// the same limit as Terraform has for portability
+		return nil, fmt.Errorf("%w: may not extend prefix by %d bits", ErrInvalidNetwork, newbits)
+	case ones+newbits > bits:
+		return nil, fmt.Errorf("%w: insufficient address space to extend prefix of %d by %d", ErrOverflow, ones, newbits)
+	case netnum < 0 || uint64(netnum) >= uint64(1)<<newbits:
+		return nil, fmt.Errorf("%w: prefix extension of %d does not accommodate a subnet numbered %d", ErrOverflow, newbits, netnum)
+	}
+
+	first := &net.IPNet{
+		IP:   base.IP,
+		Mask: net.CIDRMask(ones+newbits, bits),
+	}
+	return NextNetwork(first, netnum), nil
+}
+
+// CIDRSubnets returns consecutive subnets of the prefix extended by each of newbits,
+// the same as Terraform's cidrsubnets(prefix, newbits...) function.
+// Each subnet is aligned to its size so there may be gaps between subnets.
+// Host bits of the prefix are ignored.
+func CIDRSubnets(prefix *net.IPNet, newbits ...int) ([]*net.IPNet, error) {
+	base, ones, bits, err := cidrBase(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*net.IPNet, 0, len(newbits))
+	var current *net.IPNet
+	for _, n := range newbits {
+		switch {
+		case n < 1:
+			return nil, fmt.Errorf("%w: must extend prefix by at least one bit", ErrInvalidNetwork)
+		case n > 32:
+			// the same limit as Terraform has for portability
+			return nil, fmt.Errorf("%w: may not extend prefix by more than 32 bits", ErrInvalidNetwork)
+		case ones+n > bits:
+			version := 4
+			if bits == 8*net.IPv6len {
+				version = 6
+			}
+			return nil, fmt.Errorf("%w: would extend prefix to %d bits, which is too long for an IPv%d address",
+				ErrOverflow, ones+n, version)
+		}
+
+		mask := net.CIDRMask(ones+n, bits)
+		if current == nil {
+			out = append(out, &net.IPNet{IP: base.IP, Mask: mask})
+			current = out[0]
+			continue
+		}
+
+		// the next subnet after the one containing the last address
+		last := Broadcast(current)
+		next := NextNetwork(&net.IPNet{IP: last.Mask(mask), Mask: mask}, 1)
+		if MustCompareIP(next.IP, last) <= 0 || !base.Contains(next.IP) {
+			return nil, fmt.Errorf("%w: not enough remaining address space for a subnet with a prefix of %d bits after %s",
+				ErrExhausted, ones+n, current)
+		}
+		out = append(out, next)
+		current = next
+	}
+
+	return out, nil
+}
+
+// CIDRHost returns the hostnum-th address of the prefix,
+// the same as Terraform's cidrhost(prefix, hostnum) function.
+// Negative hostnum counts from the end: -1 is the last address.
+// Host bits of the prefix are ignored.
+func CIDRHost(prefix *net.IPNet, hostnum int) (net.IP, error) {
+	base, ones, bits, err := cidrBase(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// with 63 host bits or more any int fits
+	if hostBits := bits - ones; hostBits < 63 {
+		if size := int64(1) << hostBits; int64(hostnum) >= size || int64(hostnum) < -size {
+			return nil, fmt.Errorf("%w: prefix of %d does not accommodate a host numbered %d", ErrOverflow, ones, hostnum)
+		}
+	}
+
+	if hostnum < 0 {
+		return NextIP(Broadcast(base), hostnum+1), nil
+	}
+	return NextIP(base.IP, hostnum), nil
+}
+
+// cidrBase returns the prefix without host bits.
+func cidrBase(prefix *net.IPNet) (*net.IPNet, int, int, error) {
+	base, err := Canonicalize(prefix)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	ones, bits := base.Mask.Size()
+	return base, ones, bits, nil
+}
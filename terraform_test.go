@@ -0,0 +1,131 @@
+package ipx_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleCIDRSubnets is an example of Terraform-compatible subnetting
+func ExampleCIDRSubnets() {
+	subnets, _ := ipx.CIDRSubnets(cidr("10.1.0.0/16"), 4, 4, 8, 4)
+	fmt.Println(subnets)
+	host, _ := ipx.CIDRHost(subnets[2], -2)
+	fmt.Println(host)
+	// Output:
+	// [10.1.0.0/20 10.1.16.0/20 10.1.32.0/24 10.1.48.0/20]
+	// 10.1.32.254
+}
+
+// TestCIDRSubnet unit tests for CIDRSubnet
+func TestCIDRSubnet(tt *testing.T) {
+	tt.Run("valid", func(t *testing.T) {
+		for _, c := range []struct {
+			prefix   string
+			newbits  int
+			netnum   int
+			expected string
+		}{
+			{"172.16.0.0/12", 4, 2, "172.18.0.0/16"},
+			{"10.1.2.0/24", 4, 15, "10.1.2.240/28"},
+			{"10.1.2.3/24", 0, 0, "10.1.2.0/24"},
+			{"fd00:fd12:3456:7890::/56", 16, 162, "fd00:fd12:3456:7800:a200::/72"},
+			{"2001:db8::/32", 32, 0xffffffff, "2001:db8:ffff:ffff::/64"},
+		} {
+			got, err := ipx.CIDRSubnet(cidr(c.prefix), c.newbits, c.netnum)
+			if assert.NoError(t, err, c.prefix) {
+				assert.Equal(t, c.expected, got.String(), c.prefix)
+			}
+		}
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		_, err := ipx.CIDRSubnet(cidr("10.0.0.0/24"), 9, 0)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		assert.EqualError(t, err, "IP arithmetic overflow: insufficient address space to extend prefix of 24 by 9")
+		_, err = ipx.CIDRSubnet(cidr("10.0.0.0/24"), 2, 4)
+		assert.EqualError(t, err, "IP arithmetic overflow: prefix extension of 2 does not accommodate a subnet numbered 4")
+		_, err = ipx.CIDRSubnet(cidr("10.0.0.0/24"), 2, -1)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.CIDRSubnet(cidr("2001:db8::/32"), 33, 0)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.CIDRSubnet(cidr("2001:db8::/32"), -1, 0)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.CIDRSubnet(nil, 1, 0)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
+
+// TestCIDRSubnets unit tests for CIDRSubnets
+func TestCIDRSubnets(tt *testing.T) {
+	tt.Run("valid", func(t *testing.T) {
+		got, err := ipx.CIDRSubnets(cidr("fd00:fd12:3456:7890::/56"), 16, 16, 16, 32)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"fd00:fd12:3456:7800::/72",
+			"fd00:fd12:3456:7800:100::/72",
+			"fd00:fd12:3456:7800:200::/72",
+			"fd00:fd12:3456:7800:300::/88",
+		}, netStrings(got))
+
+		got, err = ipx.CIDRSubnets(cidr("0.0.0.0/0"), 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0.0.0.0/1", "128.0.0.0/1"}, netStrings(got))
+
+		got, err = ipx.CIDRSubnets(cidr("10.0.0.0/8"))
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		_, err := ipx.CIDRSubnets(cidr("10.0.0.0/24"), 1, 2, 2, 8)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+		assert.EqualError(t, err, "address space exhausted: not enough remaining address space"+
+			" for a subnet with a prefix of 32 bits after 10.0.0.192/26")
+		_, err = ipx.CIDRSubnets(cidr("0.0.0.0/0"), 1, 1, 1)
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+		_, err = ipx.CIDRSubnets(cidr("10.0.0.0/24"), 9)
+		assert.EqualError(t, err, "IP arithmetic overflow: would extend prefix to 33 bits, which is too long for an IPv4 address")
+		_, err = ipx.CIDRSubnets(cidr("10.0.0.0/24"), 0)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+		_, err = ipx.CIDRSubnets(cidr("2001:db8::/32"), 33)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
+
+// TestCIDRHost unit tests for CIDRHost
+func TestCIDRHost(tt *testing.T) {
+	tt.Run("valid", func(t *testing.T) {
+		for _, c := range []struct {
+			prefix   string
+			hostnum  int
+			expected string
+		}{
+			{"10.12.112.0/20", 16, "10.12.112.16"},
+			{"10.12.112.0/20", 268, "10.12.113.12"},
+			{"10.12.112.0/20", -1, "10.12.127.255"},
+			{"10.12.112.0/20", -4096, "10.12.112.0"},
+			{"10.0.0.1/32", 0, "10.0.0.1"},
+			{"fd00:fd12:3456:7890:00a2::/72", 34, "fd00:fd12:3456:7890::22"},
+			{"2001:db8::/64", -1, "2001:db8::ffff:ffff:ffff:ffff"},
+			{"::/0", -2, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"},
+		} {
+			got, err := ipx.CIDRHost(cidr(c.prefix), c.hostnum)
+			if assert.NoError(t, err, c.prefix) {
+				assert.Equal(t, c.expected, got.String(), c.prefix)
+			}
+		}
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		_, err := ipx.CIDRHost(cidr("10.12.112.0/20"), 4096)
+		assert.EqualError(t, err, "IP arithmetic overflow: prefix of 20 does not accommodate a host numbered 4096")
+		_, err = ipx.CIDRHost(cidr("10.12.112.0/20"), -4097)
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.CIDRHost(nil, 1)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
@@ -1,19 +1,37 @@
 package ipx
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
+	"net"
 )
 
+// v4InV6Prefix is the prefix of IPv4-mapped IPv6 addresses.
+var v4InV6Prefix = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}
+
 // load32 reads uint32 integer from raw 4 bytes.
 // big endian format is assumed.
+// 16 bytes IPv4-mapped IPv6 address is also accepted, it panics otherwise.
 func load32(buf []byte) uint32 {
-	l := len(buf) // TODO: consider to accept only 4 bytes!
-	return binary.BigEndian.Uint32(buf[l-4:])
+	return binary.BigEndian.Uint32(raw32(buf))
 }
 
 // store32 writes uint32 integer into the raw 4 bytes.
 // big endian format is assumed.
+// 16 bytes IPv4-mapped IPv6 address is also accepted, it panics otherwise.
 func store32(n uint32, buf []byte) {
-	l := len(buf) // TODO: consider to accpent only 4 bytes!
-	binary.BigEndian.PutUint32(buf[l-4:], n)
+	binary.BigEndian.PutUint32(raw32(buf), n)
+}
+
+// raw32 returns 4 bytes of IPv4 address
+// or panics if the buffer is not an IPv4 address.
+func raw32(buf []byte) []byte {
+	switch {
+	case len(buf) == net.IPv4len:
+		return buf
+	case len(buf) == net.IPv6len && bytes.Equal(buf[:12], v4InV6Prefix):
+		return buf[12:]
+	}
+	panic(fmt.Sprintf("ipx: %d bytes %x is not an IPv4 address", len(buf), buf))
 }
@@ -0,0 +1,136 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VLSMRequirement is a named request for one or more subnets of the same size.
+// The size is computed from Hosts if it is positive, Prefix is used otherwise.
+type VLSMRequirement struct {
+	Name   string
+	Hosts  int  // number of hosts required
+	Prefix *int // exact prefix length if Hosts is not set, nil if not set
+	Count  int  // number of subnets, 1 if not set
+}
+
+// ParseVLSMRequirement parses the requirement in one of the forms:
+//   - "app: 500 hosts" or "app: 500 hosts x 2"
+//   - "p2p: /31" or "p2p: /31 x 20"
+func ParseVLSMRequirement(s string) (VLSMRequirement, error) {
+	var r VLSMRequirement
+
+	name, spec, ok := strings.Cut(s, ":")
+	r.Name = strings.TrimSpace(name)
+	fields := strings.Fields(spec)
+	if !ok || r.Name == "" || len(fields) == 0 {
+		return r, fmt.Errorf("%w: %q", ErrInvalidRequirement, s)
+	}
+
+	var err error
+	if strings.HasPrefix(fields[0], "/") {
+		var prefix int
+		prefix, err = strconv.Atoi(fields[0][1:])
+		r.Prefix = &prefix
+		fields = fields[1:]
+	} else {
+		r.Hosts, err = strconv.Atoi(fields[0])
+		fields = fields[1:]
+		if len(fields) > 0 && (fields[0] == "hosts" || fields[0] == "host") {
+			fields = fields[1:]
+		}
+		if err == nil && r.Hosts <= 0 {
+			err = ErrInvalidRequirement
+		}
+	}
+	if err == nil && len(fields) > 0 {
+		if len(fields) != 2 || fields[0] != "x" {
+			err = ErrInvalidRequirement
+		} else if r.Count, err = strconv.Atoi(fields[1]); err == nil && r.Count <= 0 {
+			err = ErrInvalidRequirement
+		}
+	}
+	if err != nil {
+		return r, fmt.Errorf("%w: %q", ErrInvalidRequirement, s)
+	}
+
+	return r, nil
+}
+
+// PlanVLSM assigns subnets of the parent network to the requirements.
+//
+// The smallest prefix is computed for each requirement: network and
+// broadcast addresses are reserved, except the point-to-point (2 hosts)
+// and single host cases which get /31 and /32 (/127 and /128 for IPv6).
+// Subnets are packed largest-first (in order of requirements for the same size),
+// each one at the lowest free address.
+//
+// The result maps requirement names to the assigned subnets.
+// ErrExhausted is returned describing the first requirement that did not fit.
+func PlanVLSM(parent *net.IPNet, reqs []VLSMRequirement) (map[string][]*net.IPNet, error) {
+	_, v6, err := tableKey(parent)
+	if err != nil {
+		return nil, err
+	}
+	size := 8 * net.IPv4len
+	if v6 {
+		size = 8 * net.IPv6len
+	}
+
+	prefixes := make([]int, len(reqs))
+	names := make(map[string]struct{}, len(reqs))
+	for i, r := range reqs {
+		if _, ok := names[r.Name]; ok || r.Name == "" {
+			return nil, fmt.Errorf("%w: bad or duplicate name %q", ErrInvalidRequirement, r.Name)
+		}
+		names[r.Name] = struct{}{}
+
+		switch {
+		case r.Count < 0:
+			return nil, fmt.Errorf("%w: %s: negative count", ErrInvalidRequirement, r.Name)
+		case r.Hosts > 0:
+			if prefixes[i], err = PrefixForHosts(Uint128{Lo: uint64(r.Hosts)}, v6); err != nil {
+				return nil, fmt.Errorf("%w: %s: %d hosts is too many", ErrInvalidRequirement, r.Name, r.Hosts)
+			}
+		case r.Hosts == 0 && r.Prefix != nil && *r.Prefix >= 0 && *r.Prefix <= size:
+			prefixes[i] = *r.Prefix
+		default:
+			return nil, fmt.Errorf("%w: %s: no valid size", ErrInvalidRequirement, r.Name)
+		}
+	}
+
+	order := make([]int, len(reqs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return prefixes[order[i]] < prefixes[order[j]]
+	})
+
+	a, err := NewSubnetAllocator(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]*net.IPNet, len(reqs))
+	for _, i := range order {
+		r := reqs[i]
+		count := r.Count
+		if count == 0 {
+			count = 1
+		}
+		for k := 0; k < count; k++ {
+			n, err := a.Allocate(prefixes[i], v6)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s (/%d %d of %d) does not fit %s",
+					ErrExhausted, r.Name, prefixes[i], k+1, count, parent)
+			}
+			out[r.Name] = append(out[r.Name], n)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,124 @@
+package ipx_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExamplePlanVLSM is an example of VLSM planning
+func ExamplePlanVLSM() {
+	var reqs []ipx.VLSMRequirement
+	for _, s := range []string{"app: 500 hosts", "db: 60 hosts", "p2p: /31 x 2"} {
+		r, _ := ipx.ParseVLSMRequirement(s)
+		reqs = append(reqs, r)
+	}
+	plan, _ := ipx.PlanVLSM(cidr("10.0.0.0/22"), reqs)
+	fmt.Println(plan["app"], plan["db"], plan["p2p"])
+	// Output:
+	// [10.0.0.0/23] [10.0.2.0/26] [10.0.2.64/31 10.0.2.66/31]
+}
+
+// TestParseVLSMRequirement unit tests for ParseVLSMRequirement
+func TestParseVLSMRequirement(t *testing.T) {
+	for s, expected := range map[string]ipx.VLSMRequirement{
+		"app: 500 hosts":       {Name: "app", Hosts: 500},
+		"web:1 host":           {Name: "web", Hosts: 1},
+		" db : 60 ":            {Name: "db", Hosts: 60},
+		"lan: 10 hosts x 3":    {Name: "lan", Hosts: 10, Count: 3},
+		"p2p: /31 x 20":        {Name: "p2p", Prefix: intPtr(31), Count: 20},
+		"v6: /64":              {Name: "v6", Prefix: intPtr(64)},
+		"link local: /127 x 1": {Name: "link local", Prefix: intPtr(127), Count: 1},
+		"all: /0":              {Name: "all", Prefix: intPtr(0)},
+	} {
+		r, err := ipx.ParseVLSMRequirement(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, r, s)
+		}
+	}
+
+	for _, s := range []string{
+		"", "app", ": 10", "app:", "app: foo", "app: 0 hosts",
+		"app: -1", "app: 10 hosts x", "app: 10 x 0", "app: /24 y 2", "app: /x",
+	} {
+		_, err := ipx.ParseVLSMRequirement(s)
+		assert.ErrorIs(t, err, ipx.ErrInvalidRequirement, s)
+	}
+}
+
+// TestPlanVLSM unit tests for PlanVLSM
+func TestPlanVLSM(tt *testing.T) {
+	tt.Run("ipv4", func(t *testing.T) {
+		plan, err := ipx.PlanVLSM(cidr("192.168.0.0/24"), []ipx.VLSMRequirement{
+			{Name: "small", Hosts: 2},
+			{Name: "one", Hosts: 1},
+			{Name: "lan", Hosts: 62},  // exactly /26
+			{Name: "wifi", Hosts: 63}, // /25
+			{Name: "mgmt", Prefix: intPtr(28), Count: 2},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"192.168.0.0/25"}, netStrings(plan["wifi"]))
+		assert.Equal(t, []string{"192.168.0.128/26"}, netStrings(plan["lan"]))
+		assert.Equal(t, []string{"192.168.0.192/28", "192.168.0.208/28"}, netStrings(plan["mgmt"]))
+		assert.Equal(t, []string{"192.168.0.224/31"}, netStrings(plan["small"]))
+		assert.Equal(t, []string{"192.168.0.226/32"}, netStrings(plan["one"]))
+	})
+
+	tt.Run("ipv6", func(t *testing.T) {
+		plan, err := ipx.PlanVLSM(cidr("2001:db8::/48"), []ipx.VLSMRequirement{
+			{Name: "p2p", Hosts: 2, Count: 2},
+			{Name: "lan", Prefix: intPtr(64)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2001:db8::/64"}, netStrings(plan["lan"]))
+		assert.Equal(t, []string{"2001:db8:0:1::/127", "2001:db8:0:1::2/127"}, netStrings(plan["p2p"]))
+	})
+
+	tt.Run("whole_space", func(t *testing.T) {
+		plan, err := ipx.PlanVLSM(cidr("0.0.0.0/0"), []ipx.VLSMRequirement{{Name: "all", Prefix: intPtr(0)}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0.0.0.0/0"}, netStrings(plan["all"]))
+
+		_, err = ipx.PlanVLSM(cidr("10.0.0.0/8"), []ipx.VLSMRequirement{{Name: "all", Prefix: intPtr(0)}})
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+	})
+
+	tt.Run("does_not_fit", func(t *testing.T) {
+		_, err := ipx.PlanVLSM(cidr("10.0.0.0/24"), []ipx.VLSMRequirement{
+			{Name: "app", Hosts: 100},
+			{Name: "p2p", Prefix: intPtr(31), Count: 100},
+		})
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+		assert.Contains(t, err.Error(), "p2p (/31 65 of 100)")
+
+		_, err = ipx.PlanVLSM(cidr("10.0.0.0/24"), []ipx.VLSMRequirement{{Name: "app", Hosts: 300}})
+		assert.ErrorIs(t, err, ipx.ErrExhausted)
+		assert.Contains(t, err.Error(), "app (/23 1 of 1)")
+	})
+
+	tt.Run("invalid", func(t *testing.T) {
+		for _, reqs := range [][]ipx.VLSMRequirement{
+			{{Name: "", Hosts: 1}},
+			{{Name: "a", Hosts: 1}, {Name: "a", Hosts: 2}},
+			{{Name: "a"}},
+			{{Name: "a", Prefix: intPtr(33)}},
+			{{Name: "a", Prefix: intPtr(-1)}},
+			{{Name: "a", Hosts: -1}},
+			{{Name: "a", Hosts: 1, Count: -1}},
+		} {
+			_, err := ipx.PlanVLSM(cidr("10.0.0.0/8"), reqs)
+			assert.ErrorIs(t, err, ipx.ErrInvalidRequirement, reqs)
+		}
+
+		_, err := ipx.PlanVLSM(nil, nil)
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+}
+
+// intPtr returns a pointer to the value.
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,455 @@
+package ipx
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// maxWildcardBits is the maximum number of non-trailing wildcard bits
+// which can be expanded into networks (about a million of networks).
+const maxWildcardBits = 20
+
+// maxWildcardSearch limits the number of steps of SummarizeWildcard
+// looking for the fewest wildcard networks.
+const maxWildcardSearch = 1 << 16
+
+// WildcardNet is an IP address with a wildcard mask, as used by Cisco ACLs.
+// Address bits are ignored where the wildcard bits are set,
+// so the wildcard may have "holes", like `10.0.0.0 0.0.255.0`,
+// which cannot be represented by *net.IPNet.
+//
+// WildcardNet is encoded as text in `address wildcard` form.
+type WildcardNet struct {
+	IP       net.IP
+	Wildcard net.IP
+}
+
+// wildcardKey is a wildcard network as 128 bits integers, see loadIP().
+// Address bits are cleared where the wildcard bits are set.
+type wildcardKey struct {
+	addr, wc Uint128
+}
+
+// ParseWildcardNet parses the wildcard network in `10.0.0.0 0.0.255.0`,
+// `host 10.0.0.1` or `any` form. Like Cisco IPv4 ACLs, `any` is the same
+// as `0.0.0.0 255.255.255.255`.
+func ParseWildcardNet(s string) (WildcardNet, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 1 && fields[0] == "any" {
+		return WildcardNet{IP: net.IPv4zero.To4(), Wildcard: net.IPv4bcast.To4()}, nil
+	}
+	if len(fields) != 2 {
+		return WildcardNet{}, fmt.Errorf("%w: %q", ErrInvalidNetwork, s)
+	}
+
+	ip := net.ParseIP(fields[1])
+	if fields[0] == "host" && ip != nil {
+		wc := make(net.IP, net.IPv6len)
+		if v4 := ip.To4(); v4 != nil {
+			ip, wc = v4, wc[:net.IPv4len]
+		}
+		return WildcardNet{IP: ip, Wildcard: wc}, nil
+	}
+
+	w := WildcardNet{IP: net.ParseIP(fields[0]), Wildcard: ip}
+	if w.IP == nil || w.Wildcard == nil {
+		return WildcardNet{}, fmt.Errorf("%w: %q", ErrInvalidNetwork, s)
+	}
+	if _, _, err := w.key(); err != nil {
+		return WildcardNet{}, fmt.Errorf("%w: %q", err, s)
+	}
+	if v4 := w.IP.To4(); v4 != nil {
+		w.IP, w.Wildcard = v4, w.Wildcard.To4()
+	}
+	return w, nil
+}
+
+// String returns the wildcard network in `address wildcard` form.
+// Returns empty string for the zero WildcardNet.
+func (w WildcardNet) String() string {
+	if w.IP == nil && w.Wildcard == nil {
+		return ""
+	}
+	return w.IP.String() + " " + w.Wildcard.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is the same as returned by String().
+func (w WildcardNet) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The wildcard network is expected in a form accepted by ParseWildcardNet().
+// Empty text is decoded as the zero WildcardNet.
+func (w *WildcardNet) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*w = WildcardNet{}
+		return nil
+	}
+
+	out, err := ParseWildcardNet(string(text))
+	if err != nil {
+		return err
+	}
+
+	*w = out
+	return nil
+}
+
+// Contains returns true if the IP address matches the wildcard network.
+func (w WildcardNet) Contains(addr net.IP) bool {
+	key, v6, err := w.key()
+	if err != nil {
+		return false // bad wildcard network
+	}
+
+	u, uv6, err := loadIP(addr)
+	if err != nil || v6 != uv6 {
+		return false // bad address or version mismatch
+	}
+
+	return u.AndNot(key.wc).Equals(key.addr)
+}
+
+// Networks expands the wildcard network into the minimal sorted list of networks.
+// ErrOverflow is returned if the wildcard has too many holes to expand.
+func (w WildcardNet) Networks() ([]*net.IPNet, error) {
+	key, v6, err := w.key()
+	if err != nil {
+		return nil, err
+	}
+	bits := 8 * net.IPv4len
+	if v6 {
+		bits = 8 * net.IPv6len
+	}
+
+	// trailing wildcard bits are host bits of the networks,
+	// all combinations of the rest wildcard bits are enumerated
+	hostBits := key.wc.Not().TrailingZeros()
+	var holes []Uint128
+	for i := hostBits; i < bits; i++ {
+		if bit := (Uint128{Lo: 1}).Lsh(uint(i)); !key.wc.And(bit).IsZero() {
+			holes = append(holes, bit)
+		}
+	}
+	if len(holes) > maxWildcardBits {
+		return nil, fmt.Errorf("%w: %s has too many holes", ErrOverflow, w)
+	}
+
+	out := make([]*net.IPNet, 0, 1<<len(holes))
+	for k := 0; k < 1<<len(holes); k++ {
+		addr := key.addr
+		for i, bit := range holes {
+			if k&(1<<i) != 0 {
+				addr = addr.Or(bit)
+			}
+		}
+		out = append(out, &net.IPNet{
+			IP:   storeIP(addr, v6),
+			Mask: net.CIDRMask(bits-hostBits, bits),
+		})
+	}
+
+	return Collapse(out), nil
+}
+
+// SummarizeWildcard returns the fewest wildcard networks covering exactly
+// all the networks. Entries may overlap, the order does not matter for ACLs.
+// The search for the fewest entries is limited by maxWildcardSearch steps,
+// large inputs are only merged pairwise, so there may be a shorter list.
+// IPv4 entries go first, each version is sorted by address.
+func SummarizeWildcard(networks []*net.IPNet) ([]WildcardNet, error) {
+	set, err := NewIPSet(networks...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []WildcardNet
+	for _, v6 := range []bool{false, true} {
+		var keys []wildcardKey
+		for _, n := range set.spans(v6).networks(v6) {
+			first, _, _ := loadIP(n.IP)
+			ones, bits := n.Mask.Size()
+			wc := Uint128{Lo: 1}.Lsh(uint(bits - ones)).Sub64(1)
+			keys = append(keys, wildcardKey{addr: first, wc: wc})
+		}
+
+		for _, key := range minimizeWildcards(keys, v6) {
+			out = append(out, WildcardNet{
+				IP:       storeIP(key.addr, v6),
+				Wildcard: storeIP(key.wc, v6),
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// WildcardNets returns the list of wildcard networks covering exactly the set,
+// see SummarizeWildcard().
+func (s *RangeSet) WildcardNets() ([]WildcardNet, error) {
+	networks, err := s.Networks()
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeWildcard(networks)
+}
+
+// key returns the wildcard network as 128 bits integers.
+func (w WildcardNet) key() (wildcardKey, bool, error) {
+	addr, v6, err := loadIP(w.IP)
+	if err != nil {
+		return wildcardKey{}, false, err
+	}
+	wc, wcV6, err := loadIP(w.Wildcard)
+	if err != nil {
+		return wildcardKey{}, false, fmt.Errorf("%w: bad wildcard", ErrInvalidMask)
+	}
+	if v6 != wcV6 {
+		return wildcardKey{}, false, ErrVersionMismatch
+	}
+	return wildcardKey{addr: addr.AndNot(wc), wc: wc}, v6, nil
+}
+
+// mergeWildcards merges pairs of disjoint wildcard networks
+// having the same wildcard and different in a single address bit.
+// The lowest bits are merged first, so adjacent networks are merged as usual.
+func mergeWildcards(keys []wildcardKey, v6 bool) []wildcardKey {
+	bits := 8 * net.IPv4len
+	if v6 {
+		bits = 8 * net.IPv6len
+	}
+
+	for merged := true; merged; {
+		merged = false
+		sort.Slice(keys, func(i, j int) bool {
+			if c := keys[i].addr.Cmp(keys[j].addr); c != 0 {
+				return c < 0
+			}
+			return keys[i].wc.Cmp(keys[j].wc) < 0
+		})
+
+		left := make(map[wildcardKey]bool, len(keys))
+		for _, key := range keys {
+			left[key] = true
+		}
+
+		out := make([]wildcardKey, 0, len(keys))
+		for _, key := range keys {
+			if !left[key] {
+				continue // already merged
+			}
+			delete(left, key)
+
+			for i := 0; i < bits; i++ {
+				bit := Uint128{Lo: 1}.Lsh(uint(i))
+				if !key.wc.And(bit).IsZero() {
+					continue // already wildcard
+				}
+				buddy := wildcardKey{addr: key.addr.Xor(bit), wc: key.wc}
+				if left[buddy] {
+					delete(left, buddy)
+					key = wildcardKey{addr: key.addr.AndNot(bit), wc: key.wc.Or(bit)}
+					merged = true
+					break
+				}
+			}
+			out = append(out, key)
+		}
+		keys = out
+	}
+
+	return keys
+}
+
+// contains returns true if all addresses of the o wildcard network match the key.
+func (key wildcardKey) contains(o wildcardKey) bool {
+	return o.wc.AndNot(key.wc).IsZero() && o.addr.AndNot(key.wc).Equals(key.addr)
+}
+
+// intersects returns true if the wildcard networks have common addresses.
+func (key wildcardKey) intersects(o wildcardKey) bool {
+	return key.addr.Xor(o.addr).AndNot(key.wc).AndNot(o.wc).IsZero()
+}
+
+// consensus returns the wildcard network made of both networks when they
+// differ in a single address bit specified by both. The result covers
+// addresses of both networks having that bit set and unset.
+func (key wildcardKey) consensus(o wildcardKey) (wildcardKey, bool) {
+	diff := key.addr.Xor(o.addr).AndNot(key.wc).AndNot(o.wc)
+	if diff.OnesCount() != 1 {
+		return wildcardKey{}, false
+	}
+	wc := key.wc.And(o.wc).Or(diff)
+	return wildcardKey{addr: key.addr.Or(o.addr).AndNot(wc), wc: wc}, true
+}
+
+// uncovered returns an address of the wildcard network
+// not matched by any of the keys, or false if there is no such address.
+func (key wildcardKey) uncovered(keys []wildcardKey) (Uint128, bool) {
+	var near []wildcardKey
+	for _, k := range keys {
+		if k.contains(key) {
+			return Uint128{}, false
+		}
+		if k.intersects(key) {
+			near = append(near, k)
+		}
+	}
+	if len(near) == 0 {
+		return key.addr, true
+	}
+
+	// the nearest key has an address bit which is wildcard here,
+	// both halves of the network are checked
+	bit := Uint128{Lo: 1}.Lsh(uint(key.wc.AndNot(near[0].wc).TrailingZeros()))
+	lo := wildcardKey{addr: key.addr, wc: key.wc.AndNot(bit)}
+	if addr, ok := lo.uncovered(near); ok {
+		return addr, true
+	}
+	return wildcardKey{addr: key.addr.Or(bit), wc: lo.wc}.uncovered(near)
+}
+
+// minimizeWildcards returns the fewest wildcard networks covering exactly
+// the union of the keys. The keys are merged first to get a short cover quickly,
+// then all prime wildcard networks (which cannot be widened) are found
+// and the smallest cover is searched among them.
+// All the steps share the maxWildcardSearch budget, the best cover found
+// so far is used when it runs out. Too many merged keys are returned as is.
+func minimizeWildcards(keys []wildcardKey, v6 bool) []wildcardKey {
+	keys = mergeWildcards(keys, v6)
+	if len(keys)*len(keys) > maxWildcardSearch {
+		return keys // too many to search, even pairwise
+	}
+
+	budget := maxWildcardSearch
+	primes := primeWildcards(keys, &budget)
+	best := irredundantWildcards(primes, &budget)
+
+	var search func(chosen []wildcardKey)
+	search = func(chosen []wildcardKey) {
+		if budget--; budget < 0 {
+			return
+		}
+
+		// branch on the uncovered address matched by the fewest primes
+		var branch []wildcardKey
+		found := false
+		for _, key := range keys {
+			addr, ok := key.uncovered(chosen)
+			if !ok {
+				continue
+			}
+			var matched []wildcardKey
+			for _, p := range primes {
+				if p.contains(wildcardKey{addr: addr}) {
+					matched = append(matched, p)
+				}
+			}
+			if !found || len(matched) < len(branch) {
+				branch, found = matched, true
+			}
+		}
+
+		if !found {
+			if len(chosen) < len(best) {
+				best = append([]wildcardKey(nil), chosen...)
+			}
+			return
+		}
+		if len(chosen)+1 >= len(best) {
+			return // cannot be better
+		}
+		for _, p := range branch {
+			search(append(chosen, p))
+		}
+	}
+	search(nil)
+
+	sortWildcards(best)
+	return best
+}
+
+// primeWildcards returns the prime wildcard networks of the union of the keys
+// using iterated consensus: the consensus of each pair is added unless
+// it is within another network, networks within it are removed.
+// The work is limited by the budget, the result covers the union anyway.
+func primeWildcards(keys []wildcardKey, budget *int) []wildcardKey {
+	var out, queue []wildcardKey
+	alive := make(map[wildcardKey]bool)
+	add := func(key wildcardKey) {
+		*budget -= len(out)
+		for _, p := range out {
+			if p.contains(key) {
+				return
+			}
+		}
+		kept := out[:0]
+		for _, p := range out {
+			if key.contains(p) {
+				delete(alive, p)
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		out = append(kept, key)
+		queue = append(queue, key)
+		alive[key] = true
+	}
+
+	for _, key := range keys {
+		add(key)
+	}
+	for ; len(queue) > 0 && *budget > 0; queue = queue[1:] {
+		key := queue[0]
+		if !alive[key] {
+			continue // within a wider network
+		}
+		for _, p := range append([]wildcardKey(nil), out...) {
+			if *budget--; *budget < 0 {
+				break
+			}
+			if c, ok := key.consensus(p); ok {
+				add(c)
+			}
+		}
+	}
+
+	sortWildcards(out)
+	return out
+}
+
+// irredundantWildcards returns the keys without networks covered by the rest,
+// the narrowest networks are removed first.
+// The work is limited by the budget, the result covers the union anyway.
+func irredundantWildcards(keys []wildcardKey, budget *int) []wildcardKey {
+	out := append([]wildcardKey(nil), keys...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].wc.OnesCount() < out[j].wc.OnesCount()
+	})
+
+	rest := make([]wildcardKey, 0, len(out))
+	for i := 0; i < len(out) && *budget > 0; {
+		*budget -= len(out)
+		rest = append(append(rest[:0], out[:i]...), out[i+1:]...)
+		if _, ok := out[i].uncovered(rest); !ok {
+			out, rest = rest, out // reuse the buffer
+			continue
+		}
+		i++
+	}
+	return out
+}
+
+// sortWildcards sorts the keys by address and wildcard.
+func sortWildcards(keys []wildcardKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if c := keys[i].addr.Cmp(keys[j].addr); c != 0 {
+			return c < 0
+		}
+		return keys[i].wc.Cmp(keys[j].wc) < 0
+	})
+}
@@ -0,0 +1,221 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/ns1/ipx/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ExampleWildcardNet_Networks is an example of wildcard network expansion
+func ExampleWildcardNet_Networks() {
+	w, _ := ipx.ParseWildcardNet("10.0.0.0 0.0.2.255")
+	networks, _ := w.Networks()
+	fmt.Println(networks)
+	// Output:
+	// [10.0.0.0/24 10.0.2.0/24]
+}
+
+// wildcardStrings converts the wildcard networks into strings.
+func wildcardStrings(ws []ipx.WildcardNet) []string {
+	var out []string
+	for _, w := range ws {
+		out = append(out, w.String())
+	}
+	return out
+}
+
+// TestParseWildcardNet unit tests for ParseWildcardNet
+func TestParseWildcardNet(t *testing.T) {
+	w, err := ipx.ParseWildcardNet("10.0.1.5  0.0.255.0")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.1.5 0.0.255.0", w.String())
+	assert.Len(t, w.IP, net.IPv4len)
+	assert.Len(t, w.Wildcard, net.IPv4len)
+
+	w, err = ipx.ParseWildcardNet("host 2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1 ::", w.String())
+
+	for _, s := range []string{"any", " any ", "0.0.0.0 255.255.255.255"} {
+		w, err := ipx.ParseWildcardNet(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, "0.0.0.0 255.255.255.255", w.String(), s)
+		assert.True(t, w.Contains(net.ParseIP("192.0.2.1")), s)
+		assert.False(t, w.Contains(net.ParseIP("2001:db8::1")), s)
+		networks, err := w.Networks()
+		require.NoError(t, err, s)
+		assert.Equal(t, []string{"0.0.0.0/0"}, netStrings(networks), s)
+	}
+
+	for _, s := range []string{"", "any 10.0.0.0", "10.0.0.0", "10.0.0.0 foo", "foo 0.0.0.255", "10.0.0.0 ::ff", "host foo", "10.0.0.0 0.0.0.255 x"} {
+		_, err := ipx.ParseWildcardNet(s)
+		assert.Error(t, err, s)
+	}
+
+	data, err := json.Marshal([]ipx.WildcardNet{w, {}})
+	require.NoError(t, err)
+	assert.Equal(t, `["2001:db8::1 ::",""]`, string(data))
+	var out []ipx.WildcardNet
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, []string{"2001:db8::1 ::", ""}, wildcardStrings(out))
+}
+
+// TestWildcardNetContains unit tests for WildcardNet.Contains
+func TestWildcardNetContains(t *testing.T) {
+	w, err := ipx.ParseWildcardNet("10.1.0.1 0.0.255.0")
+	require.NoError(t, err)
+	for ip, expected := range map[string]bool{
+		"10.1.0.1":   true,
+		"10.1.77.1":  true,
+		"10.1.255.1": true,
+		"10.1.0.2":   false,
+		"10.2.0.1":   false,
+		"::1":        false,
+	} {
+		assert.Equal(t, expected, w.Contains(net.ParseIP(ip)), ip)
+	}
+	assert.False(t, ipx.WildcardNet{}.Contains(net.ParseIP("10.1.0.1")))
+
+	w, err = ipx.ParseWildcardNet("2001:db8::1 0:0:ffff::")
+	require.NoError(t, err)
+	assert.True(t, w.Contains(net.ParseIP("2001:db8:1234::1")))
+	assert.False(t, w.Contains(net.ParseIP("2001:db8:1234::2")))
+}
+
+// TestWildcardNetNetworks unit tests for WildcardNet.Networks
+func TestWildcardNetNetworks(tt *testing.T) {
+	tt.Run("expand", func(t *testing.T) {
+		for s, expected := range map[string][]string{
+			"10.0.0.0 0.0.0.255":       {"10.0.0.0/24"},
+			"10.0.0.9 0.0.0.0":         {"10.0.0.9/32"},
+			"10.0.0.1 0.0.3.0":         {"10.0.0.1/32", "10.0.1.1/32", "10.0.2.1/32", "10.0.3.1/32"},
+			"10.0.0.0 0.0.1.127":       {"10.0.0.0/25", "10.0.1.0/25"},
+			"0.0.0.0 255.255.255.255":  {"0.0.0.0/0"},
+			"2001:db8::5 ::1:0:0:ffff": {"2001:db8::/112", "2001:db8:0:0:1::/112"},
+		} {
+			w, err := ipx.ParseWildcardNet(s)
+			require.NoError(t, err, s)
+			networks, err := w.Networks()
+			if assert.NoError(t, err, s) {
+				assert.Equal(t, expected, netStrings(networks), s)
+			}
+		}
+	})
+
+	tt.Run("too_many", func(t *testing.T) {
+		w, err := ipx.ParseWildcardNet("2001:db8::1 ffff:ffff::")
+		require.NoError(t, err)
+		_, err = w.Networks()
+		assert.ErrorIs(t, err, ipx.ErrOverflow)
+		_, err = ipx.WildcardNet{IP: net.ParseIP("10.0.0.0"), Wildcard: net.ParseIP("::")}.Networks()
+		assert.ErrorIs(t, err, ipx.ErrVersionMismatch)
+	})
+}
+
+// TestSummarizeWildcard unit tests for SummarizeWildcard
+func TestSummarizeWildcard(tt *testing.T) {
+	tt.Run("round_trip", func(t *testing.T) {
+		for _, s := range []string{
+			"10.0.0.0 0.0.2.255",
+			"10.1.0.1 0.0.255.0",
+			"192.168.0.0 0.0.0.255",
+			"2001:db8::1 0:0:f::",
+		} {
+			w, err := ipx.ParseWildcardNet(s)
+			require.NoError(t, err)
+			networks, err := w.Networks()
+			require.NoError(t, err)
+			got, err := ipx.SummarizeWildcard(networks)
+			require.NoError(t, err)
+			assert.Equal(t, []string{s}, wildcardStrings(got), s)
+		}
+	})
+
+	tt.Run("mixed", func(t *testing.T) {
+		got, err := ipx.SummarizeWildcard([]*net.IPNet{
+			cidr("10.0.1.0/24"),
+			cidr("10.0.3.0/24"),
+			cidr("10.0.3.0/25"), // overlapped
+			cidr("10.0.8.0/24"),
+			cidr("2001:db8::/64"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"10.0.1.0 0.0.2.255",
+			"10.0.8.0 0.0.0.255",
+			"2001:db8:: ::ffff:ffff:ffff:ffff",
+		}, wildcardStrings(got))
+
+		got, err = ipx.SummarizeWildcard(nil)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		_, err = ipx.SummarizeWildcard([]*net.IPNet{nil})
+		assert.ErrorIs(t, err, ipx.ErrInvalidNetwork)
+	})
+
+	tt.Run("fewest", func(t *testing.T) {
+		// merging the adjacent .1 and .3 first gives 3 entries
+		got, err := ipx.SummarizeWildcard([]*net.IPNet{
+			cidr("10.0.0.1/32"),
+			cidr("10.0.0.3/32"),
+			cidr("10.0.0.5/32"),
+			cidr("10.0.0.11/32"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1 0.0.0.4", "10.0.0.3 0.0.0.8"}, wildcardStrings(got))
+
+		// entries may overlap
+		got, err = ipx.SummarizeWildcard([]*net.IPNet{
+			cidr("10.0.0.0/32"),
+			cidr("10.0.0.2/31"),
+			cidr("10.0.0.7/32"),
+			cidr("10.0.0.9/32"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0 0.0.0.2", "10.0.0.3 0.0.0.4", "10.0.0.9 0.0.0.0"}, wildcardStrings(got))
+
+		// the whole space
+		got, err = ipx.SummarizeWildcard([]*net.IPNet{cidr("0.0.0.0/1"), cidr("128.0.0.0/1")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0.0.0.0 255.255.255.255"}, wildcardStrings(got))
+	})
+
+	tt.Run("large", func(t *testing.T) {
+		// too many entries to search, merged pairwise only
+		var networks []*net.IPNet
+		for i := 0; i < 3000; i += 3 {
+			ip := net.IPv4(10, 0, byte(i>>8), byte(i)).To4()
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(32, 32)})
+		}
+		got, err := ipx.SummarizeWildcard(networks)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(got), len(networks))
+
+		set, err := ipx.NewIPSet(networks...)
+		require.NoError(t, err)
+		for i := 0; i < 3000; i++ {
+			ip := net.IPv4(10, 0, byte(i>>8), byte(i))
+			matched := false
+			for _, w := range got {
+				matched = matched || w.Contains(ip)
+			}
+			assert.Equal(t, set.Contains(ip), matched, ip)
+		}
+	})
+
+	tt.Run("range_set", func(t *testing.T) {
+		set, err := ipx.NewRangeSet(
+			ipx.NewRange(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.1")),
+			ipx.NewRange(net.ParseIP("10.0.0.3"), net.ParseIP("10.0.0.3")),
+		)
+		require.NoError(t, err)
+		got, err := set.WildcardNets()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1 0.0.0.2"}, wildcardStrings(got))
+	})
+}